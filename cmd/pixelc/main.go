@@ -1,25 +1,72 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
 
 	"pixelc/core/compiler"
+	"pixelc/core/exporter"
+	"pixelc/pkg/jsonconf"
 	"pixelc/pkg/model"
 )
 
 type cliConfigFile struct {
-	Connectivity int      `json:"connectivity"`
-	Padding      int      `json:"padding"`
-	PivotMode    string   `json:"pivotMode"`
-	PowerOfTwo   bool     `json:"powerOfTwo"`
-	Preset       string   `json:"preset"`
-	FPS          int      `json:"fps"`
-	Ignore       []string `json:"ignore"`
+	Connectivity int        `json:"connectivity"`
+	Padding      int        `json:"padding"`
+	PivotMode    string     `json:"pivotMode"`
+	PowerOfTwo   bool       `json:"powerOfTwo"`
+	Preset       string     `json:"preset"`
+	FPS          int        `json:"fps"`
+	Ignore       []string   `json:"ignore"`
+	Rules        []cliRule  `json:"rules"`
+	Atlases      []cliAtlas `json:"atlases"`
+	Scales       []float64  `json:"scales"`
+	ScaleFilter  string     `json:"scaleFilter"`
+
+	Collision          bool     `json:"collision"`
+	CollisionTolerance float64  `json:"collisionTolerance"`
+	CollisionConvex    bool     `json:"collisionConvex"`
+	Mesh               bool     `json:"mesh"`
+	MeshMaxVertices    int      `json:"meshMaxVertices"`
+	Tileset            string   `json:"tileset"`
+	UnityMeta          bool     `json:"unityMeta"`
+	UnityGUIDSeed      string   `json:"unityGuidSeed"`
+	Codegen            []string `json:"codegen"`
+	Embed              string   `json:"embed"`
+	PluginTimeout      string   `json:"pluginTimeout"`
+}
+
+type cliRule struct {
+	Match     string     `json:"match"`
+	PivotMode string     `json:"pivotMode"`
+	Trim      *bool      `json:"trim"`
+	Border    *cliBorder `json:"border"`
+}
+
+type cliAtlas struct {
+	Name       string   `json:"name"`
+	Match      []string `json:"match"`
+	Folder     string   `json:"folder"`
+	Padding    *int     `json:"padding"`
+	PivotMode  string   `json:"pivotMode"`
+	PowerOfTwo *bool    `json:"powerOfTwo"`
+}
+
+type cliBorder struct {
+	Left   int `json:"left"`
+	Top    int `json:"top"`
+	Right  int `json:"right"`
+	Bottom int `json:"bottom"`
 }
 
 type stringList []string
@@ -40,6 +87,10 @@ func run(args []string, stdout io.Writer, stderr io.Writer) int {
 	switch args[0] {
 	case "compile":
 		return runCompile(args[1:], stdout, stderr)
+	case "watch":
+		return runWatch(args[1:], stdout, stderr)
+	case "config":
+		return runConfig(args[1:], stdout, stderr)
 	case "version":
 		fmt.Fprintln(stdout, compiler.VersionString())
 		return 0
@@ -62,96 +113,282 @@ func runDoctor(stdout io.Writer, stderr io.Writer) int {
 	return 0
 }
 
+// compileArgs is the parsed command line shared by compile and watch.
+type compileArgs struct {
+	inputPath string
+	cfg       model.Config
+	opts      compiler.BatchOptions
+	batch     bool
+	sources   map[string]string // origin of explicitly set settings, for config explain
+}
+
 func runCompile(args []string, stdout, stderr io.Writer) int {
-	if len(args) == 0 {
-		fmt.Fprintln(stderr, "compile requires exactly one input path")
+	ca, ok := parseCompileArgs("compile", args, stderr, nil)
+	if !ok {
+		return 1
+	}
+	if ca.opts.OutDir == "" {
+		fmt.Fprintln(stderr, "--out is required")
 		return 1
 	}
+	if ca.batch {
+		return runBatchCompile(ca.inputPath, ca.cfg, ca.opts, stdout, stderr)
+	}
+	return runSingleCompile(ca.inputPath, ca.opts.OutDir, ca.cfg, ca.opts.DryRun, ca.opts.WriteReport, stdout, stderr)
+}
+
+// parseCompileArgs parses "<input> [flags]"; extra registers flags only the
+// named command accepts.
+func parseCompileArgs(name string, args []string, stderr io.Writer, extra func(*flag.FlagSet)) (compileArgs, bool) {
+	if len(args) == 0 {
+		fmt.Fprintf(stderr, "%s requires exactly one input path\n", name)
+		return compileArgs{}, false
+	}
 	inputPath := args[0]
 	args = args[1:]
 
 	cfgFilePath := detectConfigPath(args)
-	fileCfg := cliConfigFile{Connectivity: 4, PivotMode: "center", Preset: "unity", FPS: 12}
+	fileCfg := cliConfigFile{Connectivity: 4, PivotMode: "center", Preset: "unity", FPS: 12, CollisionTolerance: 1, MeshMaxVertices: 32}
+	var fileKeys map[string]bool
 	if cfgFilePath != "" {
 		var err error
-		fileCfg, err = loadCLIConfig(cfgFilePath)
+		fileCfg, fileKeys, err = loadCLIConfig(cfgFilePath)
 		if err != nil {
 			fmt.Fprintf(stderr, "config load error: %v\n", err)
-			return 1
+			return compileArgs{}, false
 		}
 	}
 
-	fs := flag.NewFlagSet("compile", flag.ContinueOnError)
+	var filePluginTimeout time.Duration
+	if fileCfg.PluginTimeout != "" {
+		var err error
+		if filePluginTimeout, err = time.ParseDuration(fileCfg.PluginTimeout); err != nil {
+			fmt.Fprintf(stderr, "config load error: pluginTimeout: %v\n", err)
+			return compileArgs{}, false
+		}
+	}
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
 	fs.SetOutput(stderr)
 	outDir := fs.String("out", "", "output directory")
-	preset := fs.String("preset", fileCfg.Preset, "output preset")
+	preset := fs.String("preset", fileCfg.Preset, "output preset(s), comma-separated: "+strings.Join(exporter.RegisteredNames(), ", "))
 	padding := fs.Int("padding", fileCfg.Padding, "atlas padding")
 	connectivity := fs.Int("connectivity", fileCfg.Connectivity, "pixel connectivity (4 or 8)")
 	pivot := fs.String("pivot", fileCfg.PivotMode, "pivot mode")
 	power2 := fs.Bool("power2", fileCfg.PowerOfTwo, "power-of-two atlas dimensions")
 	fps := fs.Int("fps", fileCfg.FPS, "animation fps")
+	collision := fs.Bool("collision", fileCfg.Collision, "trace collision polygons from sprite alpha")
+	collisionTolerance := fs.Float64("collision-tolerance", fileCfg.CollisionTolerance, "collision polygon simplification tolerance in pixels")
+	collisionConvex := fs.Bool("collision-convex", fileCfg.CollisionConvex, "split collision polygons into convex parts")
+	meshOn := fs.Bool("mesh", fileCfg.Mesh, "build tight per-sprite triangle meshes")
+	meshMaxVertices := fs.Int("mesh-max-vertices", fileCfg.MeshMaxVertices, "vertex budget per sprite mesh")
+	tileset := fs.String("tileset", fileCfg.Tileset, "also write a Tiled tileset (collection or grid)")
+	unityMeta := fs.Bool("unity-meta", fileCfg.UnityMeta, "also write a Unity atlas.png.meta importer")
+	unityGUIDSeed := fs.String("unity-guid-seed", fileCfg.UnityGUIDSeed, "prefix for the unit name that seeds the Unity asset guid")
+	codegen := fs.String("codegen", strings.Join(fileCfg.Codegen, ","), "also write sprite constants (csharp, gdscript, typescript, go; comma-separated)")
+	embed := fs.String("embed", fileCfg.Embed, "also write the atlas as source (go, go-bytes, c)")
+	scales := fs.String("scales", formatScales(fileCfg.Scales), "also write the atlas at these scales as atlas@<scale>x.png (comma-separated, e.g. 2,4 or 0.5)")
+	scaleFilter := fs.String("scale-filter", fileCfg.ScaleFilter, "resample --scales with nearest or area (default nearest to enlarge, area to shrink)")
+	pluginTimeout := fs.Duration("plugin-timeout", filePluginTimeout, "time limit for exec: preset plugins (default 30s)")
 	batch := fs.Bool("batch", false, "batch compile recursive directories")
 	dryRun := fs.Bool("dry-run", false, "plan outputs without writing files")
 	report := fs.Bool("report", false, "write report.json")
+	force := fs.Bool("force", false, "rebuild every batch unit, ignoring the build cache")
+	jobs := fs.Int("jobs", runtime.GOMAXPROCS(0), "batch units compiled in parallel")
+	keepGoing := fs.Bool("keep-going", false, "compile every batch unit and summarize failures instead of stopping at the first")
 	_ = fs.String("config", "", "config file path")
 	ignores := stringList{}
 	ignores = append(ignores, fileCfg.Ignore...)
 	fs.Var(&ignores, "ignore", "ignore glob pattern (repeatable)")
+	if extra != nil {
+		extra(fs)
+	}
 	if err := fs.Parse(args); err != nil {
-		return 1
+		return compileArgs{}, false
 	}
 	if fs.NArg() != 0 {
-		fmt.Fprintln(stderr, "unexpected extra compile arguments")
-		return 1
+		fmt.Fprintf(stderr, "unexpected extra %s arguments\n", name)
+		return compileArgs{}, false
 	}
-	if *outDir == "" {
-		fmt.Fprintln(stderr, "--out is required")
-		return 1
+	if *jobs < 1 {
+		fmt.Fprintln(stderr, "--jobs must be at least 1")
+		return compileArgs{}, false
+	}
+	scaleList, err := parseScales(*scales)
+	if err != nil {
+		fmt.Fprintf(stderr, "--scales: %v\n", err)
+		return compileArgs{}, false
 	}
 
-	cfg := model.Config{Connectivity: *connectivity, Padding: *padding, PivotMode: *pivot, PowerOfTwo: *power2, Preset: *preset, FPS: *fps}
-	if err := cfg.Validate(); err != nil {
+	cfg := model.Config{Connectivity: *connectivity, Padding: *padding, PivotMode: *pivot, PowerOfTwo: *power2, Preset: *preset, FPS: *fps, Rules: toModelRules(fileCfg.Rules), Atlases: toModelAtlases(fileCfg.Atlases), Scales: scaleList, ScaleFilter: *scaleFilter,
+		Collision: *collision, CollisionTolerance: *collisionTolerance, CollisionConvex: *collisionConvex,
+		Mesh: *meshOn, MeshMaxVertices: *meshMaxVertices, Tileset: *tileset, UnityMeta: *unityMeta, UnityGUIDSeed: *unityGUIDSeed, Codegen: splitList(*codegen), Embed: *embed, PluginTimeout: *pluginTimeout}
+	if err := compiler.ValidateConfig(cfg); err != nil {
 		fmt.Fprintf(stderr, "config validation error: %v\n", err)
-		return 1
+		return compileArgs{}, false
 	}
-
-	if *batch {
-		return runBatchCompile(inputPath, *outDir, cfg, []string(ignores), *dryRun, *report, stdout, stderr)
+	opts := compiler.BatchOptions{OutDir: *outDir, IgnorePatterns: []string(ignores), DryRun: *dryRun, WriteReport: *report, Force: *force, Jobs: *jobs, KeepGoing: *keepGoing}
+	sources := map[string]string{}
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for key, flagName := range map[string]string{"padding": "padding", "pivotMode": "pivot", "preset": "preset", "fps": "fps", "ignore": "ignore"} {
+		if set[flagName] {
+			sources[key] = "--" + flagName
+		} else if fileKeys[key] {
+			sources[key] = cfgFilePath
+		}
 	}
-	return runSingleCompile(inputPath, *outDir, cfg, *dryRun, *report, stdout, stderr)
+	return compileArgs{inputPath: inputPath, cfg: cfg, opts: opts, batch: *batch, sources: sources}, true
 }
 
 func runSingleCompile(inputPath, outDir string, cfg model.Config, dryRun, writeReport bool, stdout, stderr io.Writer) int {
-	atlas, atlasImg, presetJSON, err := compiler.Compile(inputPath, cfg)
+	b, err := compiler.CompileBuild(inputPath, cfg)
 	if err != nil {
 		fmt.Fprintf(stderr, "compile failed: %v\n", err)
 		return 1
 	}
+	named := ""
+	if len(b.Named) > 0 {
+		names := make([]string, 0, len(b.Named))
+		for _, a := range b.Named {
+			names = append(names, fmt.Sprintf("%s:%dx%d", a.Name, a.Atlas.Width, a.Atlas.Height))
+		}
+		named = " atlases=" + strings.Join(names, ",")
+	}
 	if dryRun {
-		fmt.Fprintf(stdout, "dry-run sprites=%d atlas=%dx%d out=%s\n", len(atlas.Sprites), atlas.Width, atlas.Height, outDir)
+		fmt.Fprintf(stdout, "dry-run sprites=%d atlas=%dx%d%s out=%s\n", b.SpriteCount(), b.Atlas.Width, b.Atlas.Height, named, outDir)
 		return 0
 	}
-	if err := compiler.WriteOutputs(outDir, atlasImg, presetJSON); err != nil {
+	if err := compiler.WriteFiles(outDir, b.Image, b.Files); err != nil {
 		fmt.Fprintf(stderr, "compile failed: %v\n", err)
 		return 1
 	}
 	if writeReport {
-		if _, err := compiler.WriteSingleReport(outDir, filepath.Base(inputPath), *atlas, atlasImg, presetJSON); err != nil {
+		if _, err := compiler.WriteSingleReport(outDir, filepath.Base(inputPath), b); err != nil {
 			fmt.Fprintf(stderr, "compile failed: %v\n", err)
 			return 1
 		}
 	}
-	fmt.Fprintf(stdout, "compiled sprites=%d atlas=%dx%d wrote=atlas.png,atlas.json\n", len(atlas.Sprites), atlas.Width, atlas.Height)
+	fmt.Fprintf(stdout, "compiled sprites=%d atlas=%dx%d%s wrote=%s\n", b.SpriteCount(), b.Atlas.Width, b.Atlas.Height, named, strings.Join(compiler.FileNames(b.Image, b.Files), ","))
 	return 0
 }
 
-func runBatchCompile(inputPath, outDir string, cfg model.Config, ignores []string, dryRun, writeReport bool, stdout, stderr io.Writer) int {
-	res, err := compiler.CompileBatch(inputPath, cfg, compiler.BatchOptions{OutDir: outDir, IgnorePatterns: ignores, DryRun: dryRun, WriteReport: writeReport})
+func runBatchCompile(inputPath string, cfg model.Config, opts compiler.BatchOptions, stdout, stderr io.Writer) int {
+	res, err := compiler.CompileBatch(inputPath, cfg, opts)
 	if err != nil {
 		fmt.Fprintf(stderr, "batch compile failed: %v\n", err)
 		return 1
 	}
-	fmt.Fprintf(stdout, "batch units=%d out=%s dry_run=%v\n", len(res.Units), outDir, dryRun)
+	cached := 0
+	for _, u := range res.Units {
+		if u.Cached {
+			cached++
+		}
+	}
+	fmt.Fprintf(stdout, "batch units=%d cached=%d failed=%d out=%s dry_run=%v\n", len(res.Units), cached, len(res.Failures), opts.OutDir, opts.DryRun)
+	if len(res.Failures) > 0 {
+		fmt.Fprintf(stderr, "batch failed units=%d\n", len(res.Failures))
+		for _, f := range res.Failures {
+			fmt.Fprintf(stderr, "  %s [%s]: %s\n", f.UnitName, f.Stage, f.Message)
+		}
+		return 2
+	}
+	return 0
+}
+
+func runWatch(args []string, stdout, stderr io.Writer) int {
+	var wopts compiler.WatchOptions
+	ca, ok := parseCompileArgs("watch", args, stderr, func(fs *flag.FlagSet) {
+		fs.DurationVar(&wopts.Interval, "interval", 500*time.Millisecond, "how often to poll the input for changes")
+		fs.DurationVar(&wopts.Debounce, "debounce", 200*time.Millisecond, "wait this long after the last change before rebuilding")
+	})
+	if !ok {
+		return 1
+	}
+	if ca.opts.OutDir == "" {
+		fmt.Fprintln(stderr, "--out is required")
+		return 1
+	}
+	if info, err := os.Stat(ca.inputPath); err != nil || !info.IsDir() {
+		fmt.Fprintln(stderr, "watch requires an input directory")
+		return 1
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	fmt.Fprintf(stdout, "watching %s out=%s\n", ca.inputPath, ca.opts.OutDir)
+	err := compiler.Watch(ctx, ca.inputPath, ca.cfg, ca.opts, wopts, func(ev compiler.WatchEvent) {
+		fmt.Fprintln(stdout, watchLine(ev))
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "watch failed: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// watchLine is the one-line summary printed after each rebuild.
+func watchLine(ev compiler.WatchEvent) string {
+	took := ev.Duration.Round(time.Millisecond)
+	if ev.Err != nil {
+		return fmt.Sprintf("rebuild time=%s error=%q", took, ev.Err.Error())
+	}
+	built := make([]string, 0)
+	cached := 0
+	for _, u := range ev.Result.Units {
+		if u.Cached {
+			cached++
+		} else {
+			built = append(built, u.UnitName)
+		}
+	}
+	line := fmt.Sprintf("rebuild time=%s built=%s cached=%d failed=%d", took, strings.Join(built, ","), cached, len(ev.Result.Failures))
+	if len(ev.Result.Failures) > 0 {
+		errs := make([]string, 0, len(ev.Result.Failures))
+		for _, f := range ev.Result.Failures {
+			errs = append(errs, fmt.Sprintf("%s [%s]: %s", f.UnitName, f.Stage, f.Message))
+		}
+		line += fmt.Sprintf(" error=%q", strings.Join(errs, "; "))
+	}
+	return line
+}
+
+func runConfig(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 1 && args[0] == "schema" {
+		schema, err := compiler.DirConfigSchema()
+		if err != nil {
+			fmt.Fprintf(stderr, "config schema failed: %v\n", err)
+			return 1
+		}
+		fmt.Fprintln(stdout, string(schema))
+		return 0
+	}
+	if len(args) == 0 || args[0] != "explain" {
+		fmt.Fprintln(stderr, "usage: pixelc config explain <unit> [--root <dir>] [flags]\n       pixelc config schema")
+		return 1
+	}
+	root := "."
+	ca, ok := parseCompileArgs("config explain", args[1:], stderr, func(fs *flag.FlagSet) {
+		fs.StringVar(&root, "root", ".", "batch input directory the unit belongs to")
+	})
+	if !ok {
+		return 1
+	}
+	unit := ca.inputPath
+	if rel, err := filepath.Rel(root, unit); err == nil {
+		unit = rel
+	}
+	ex, err := compiler.ExplainUnit(root, unit, ca.cfg, ca.opts.IgnorePatterns, ca.sources)
+	if err != nil {
+		fmt.Fprintf(stderr, "config explain failed: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "unit %s\n", filepath.ToSlash(ex.Unit))
+	if len(ex.Chain) > 0 {
+		fmt.Fprintf(stdout, "chain %s\n", strings.Join(ex.Chain, " -> "))
+	}
+	for _, v := range ex.Values {
+		fmt.Fprintf(stdout, "%s=%s (%s)\n", v.Key, v.Value, v.Source)
+	}
 	return 0
 }
 
@@ -164,30 +401,91 @@ func detectConfigPath(args []string) string {
 	return ""
 }
 
-func loadCLIConfig(path string) (cliConfigFile, error) {
+// loadCLIConfig also returns the keys present in the file.
+func loadCLIConfig(path string) (cliConfigFile, map[string]bool, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return cliConfigFile{}, err
+		return cliConfigFile{}, nil, err
+	}
+	// Absent and null keys keep these defaults; explicit values, zero
+	// included, are validated as written.
+	cfg := cliConfigFile{Connectivity: 4, PivotMode: "center", Preset: "unity", FPS: 12, CollisionTolerance: 1, MeshMaxVertices: 32}
+	if err := jsonconf.Decode(data, &cfg); err != nil {
+		return cliConfigFile{}, nil, fmt.Errorf("%s:%w", path, err)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return cliConfigFile{}, nil, err
 	}
-	cfg := cliConfigFile{Connectivity: 4, PivotMode: "center", Preset: "unity", FPS: 12}
-	if err := json.Unmarshal(data, &cfg); err != nil {
-		return cliConfigFile{}, err
+	keys := make(map[string]bool, len(raw))
+	for k, v := range raw {
+		keys[k] = string(v) != "null"
 	}
-	if cfg.Preset == "" {
-		cfg.Preset = "unity"
+	return cfg, keys, nil
+}
+
+func splitList(s string) []string {
+	out := make([]string, 0)
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func formatScales(scales []float64) string {
+	parts := make([]string, 0, len(scales))
+	for _, s := range scales {
+		parts = append(parts, strconv.FormatFloat(s, 'g', -1, 64))
 	}
-	if cfg.PivotMode == "" {
-		cfg.PivotMode = "center"
+	return strings.Join(parts, ",")
+}
+
+func parseScales(s string) ([]float64, error) {
+	var out []float64
+	for _, part := range splitList(s) {
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scale %q", part)
+		}
+		out = append(out, v)
 	}
-	if cfg.Connectivity == 0 {
-		cfg.Connectivity = 4
+	return out, nil
+}
+
+func toModelRules(rules []cliRule) []model.SpriteRule {
+	out := make([]model.SpriteRule, 0, len(rules))
+	for _, r := range rules {
+		rule := model.SpriteRule{Match: r.Match, PivotMode: r.PivotMode, NoTrim: r.Trim != nil && !*r.Trim}
+		if r.Border != nil {
+			rule.Border = model.Border{Left: r.Border.Left, Top: r.Border.Top, Right: r.Border.Right, Bottom: r.Border.Bottom}
+		}
+		out = append(out, rule)
 	}
-	if cfg.FPS == 0 {
-		cfg.FPS = 12
+	return out
+}
+
+func toModelAtlases(atlases []cliAtlas) []model.AtlasGroup {
+	out := make([]model.AtlasGroup, 0, len(atlases))
+	for _, a := range atlases {
+		out = append(out, model.AtlasGroup{Name: a.Name, Match: a.Match, Folder: a.Folder, Padding: a.Padding, PivotMode: a.PivotMode, PowerOfTwo: a.PowerOfTwo})
 	}
-	return cfg, nil
+	return out
 }
 
 func printHelp(w io.Writer) {
-	fmt.Fprintln(w, "pixelc compile <input> --out <dir> [flags]\npixelc version\npixelc doctor")
+	fmt.Fprintln(w, "pixelc compile <input> --out <dir> [flags]\npixelc watch <dir> --out <dir> [flags] [--interval 500ms] [--debounce 200ms]\npixelc config explain <unit> [--root <dir>] [flags]\npixelc config schema\npixelc version\npixelc doctor")
+	fmt.Fprintln(w, "\npresets (--preset name[,name...]):")
+	for _, e := range exporter.Registered() {
+		line := fmt.Sprintf("  %-20s %s", e.Name(), strings.Join(e.Files(), ", "))
+		opts := make([]string, 0)
+		for _, o := range e.Options() {
+			opts = append(opts, o.Name)
+		}
+		if len(opts) > 0 {
+			line += " (options: " + strings.Join(opts, ", ") + ")"
+		}
+		fmt.Fprintln(w, line)
+	}
 }
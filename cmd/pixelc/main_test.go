@@ -1,7 +1,10 @@
 package main
 
 import (
+	"bufio"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"image"
 	"image/color"
 	"os"
@@ -9,6 +12,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"pixelc/internal/imageutil"
 )
@@ -31,9 +35,30 @@ func TestMain(m *testing.M) {
 
 func TestHelpExitsZero(t *testing.T) {
 	cmd := exec.Command(testBinary, "--help")
-	if out, err := cmd.CombinedOutput(); err != nil {
+	out, err := cmd.CombinedOutput()
+	if err != nil {
 		t.Fatalf("expected help to exit 0 err=%v out=%s", err, out)
 	}
+	if !strings.Contains(string(out), "libgdx") || !strings.Contains(string(out), "atlas.css, preview.html") {
+		t.Fatalf("help does not list registered presets: %s", out)
+	}
+}
+
+func TestCompileMultiplePresets(t *testing.T) {
+	input := writeTempPNG(t)
+	outDir := filepath.Join(t.TempDir(), "out")
+	cmd := exec.Command(testBinary, "compile", input, "--out", outDir, "--preset", "unity,cocos2d")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("compile failed err=%v out=%s", err, out)
+	}
+	if !strings.Contains(string(out), "wrote=atlas.png,atlas.json,atlas.plist") {
+		t.Fatalf("unexpected output: %s", out)
+	}
+	cmd = exec.Command(testBinary, "compile", input, "--out", outDir, "--preset", "unity,texturepacker-hash")
+	if out, err := cmd.CombinedOutput(); err == nil || !strings.Contains(string(out), "both write atlas.json") {
+		t.Fatalf("expected file conflict error err=%v out=%s", err, out)
+	}
 }
 
 func TestVersionAndDoctor(t *testing.T) {
@@ -67,6 +92,42 @@ func TestCompileWritesOutputsAndReport(t *testing.T) {
 	}
 }
 
+func TestCompileLibGDXPresetWritesAtlasText(t *testing.T) {
+	input := writeTempPNG(t)
+	outDir := filepath.Join(t.TempDir(), "out")
+	cmd := exec.Command(testBinary, "compile", input, "--out", outDir, "--preset", "libgdx")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("expected success err=%v out=%s", err, out)
+	}
+	if !strings.Contains(string(out), "wrote=atlas.png,atlas.atlas") {
+		t.Fatalf("unexpected output: %s", out)
+	}
+	data, _ := os.ReadFile(filepath.Join(outDir, "atlas.atlas"))
+	if !strings.HasPrefix(string(data), "atlas.png\nsize:") {
+		t.Fatalf("unexpected atlas text: %s", data)
+	}
+}
+
+func TestCompileTilesetGrid(t *testing.T) {
+	root := t.TempDir()
+	writePNGAt(t, filepath.Join(root, "water_001.png"))
+	writePNGAt(t, filepath.Join(root, "water_002.png"))
+	outDir := filepath.Join(t.TempDir(), "out")
+	cmd := exec.Command(testBinary, "compile", root, "--out", outDir, "--tileset", "grid")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("expected success err=%v out=%s", err, out)
+	}
+	for _, f := range []string{"atlas.json", "atlas.tsx", "tileset.png"} {
+		assertExists(t, filepath.Join(outDir, f))
+	}
+	data, _ := os.ReadFile(filepath.Join(outDir, "atlas.tsx"))
+	if !strings.Contains(string(data), `tilewidth="4" tileheight="4"`) || !strings.Contains(string(data), "<animation>") {
+		t.Fatalf("unexpected tileset: %s", data)
+	}
+}
+
 func TestCompileDryRunNoFiles(t *testing.T) {
 	input := writeTempPNG(t)
 	outDir := filepath.Join(t.TempDir(), "out")
@@ -98,6 +159,280 @@ func TestBatchConfigAndIgnore(t *testing.T) {
 	}
 }
 
+func TestBatchCacheAndForce(t *testing.T) {
+	root := t.TempDir()
+	writePNGAt(t, filepath.Join(root, "a", "player_idle_001.png"))
+	outDir := filepath.Join(t.TempDir(), "out")
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{nil, "cached=0"},
+		{nil, "cached=1"},
+		{[]string{"--force", "--jobs", "2"}, "cached=0"},
+	} {
+		args := append([]string{"compile", root, "--batch", "--out", outDir}, tc.args...)
+		out, err := exec.Command(testBinary, args...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("batch failed err=%v out=%s", err, out)
+		}
+		if !strings.Contains(string(out), tc.want) {
+			t.Fatalf("args %v: expected %s got %s", tc.args, tc.want, out)
+		}
+	}
+}
+
+func TestBatchKeepGoing(t *testing.T) {
+	root := t.TempDir()
+	writePNGAt(t, filepath.Join(root, "a", "player_idle_001.png"))
+	writePNGAt(t, filepath.Join(root, "b", "player_idle_001.png"))
+	_ = os.WriteFile(filepath.Join(root, "a", "broken.png"), []byte("not a png"), 0o644)
+	outDir := filepath.Join(t.TempDir(), "out")
+	cmd := exec.Command(testBinary, "compile", root, "--batch", "--out", outDir, "--keep-going")
+	out, err := cmd.CombinedOutput()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 2 {
+		t.Fatalf("expected exit code 2 got err=%v out=%s", err, out)
+	}
+	if !strings.Contains(string(out), "failed=1") || !strings.Contains(string(out), "a [load]: load frame broken.png") {
+		t.Fatalf("missing failure summary: %s", out)
+	}
+	assertExists(t, filepath.Join(outDir, "b", "atlas.json"))
+}
+
+func TestWatchRebuildsOnChange(t *testing.T) {
+	root := t.TempDir()
+	writePNGAt(t, filepath.Join(root, "a", "player_idle_001.png"))
+	writePNGAt(t, filepath.Join(root, "b", "player_idle_001.png"))
+	outDir := filepath.Join(t.TempDir(), "out")
+	cmd := exec.Command(testBinary, "watch", root, "--out", outDir, "--interval", "20ms", "--debounce", "40ms")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+	lines := make(chan string)
+	go func() {
+		sc := bufio.NewScanner(stdout)
+		for sc.Scan() {
+			lines <- sc.Text()
+		}
+		close(lines)
+	}()
+	next := func() string {
+		t.Helper()
+		for {
+			select {
+			case line, ok := <-lines:
+				if !ok {
+					t.Fatal("watch exited early")
+				}
+				if strings.HasPrefix(line, "rebuild ") {
+					return line
+				}
+			case <-time.After(10 * time.Second):
+				t.Fatal("timed out waiting for a rebuild")
+			}
+		}
+	}
+
+	if line := next(); !strings.Contains(line, "built=a,b") || !strings.Contains(line, "failed=0") {
+		t.Fatalf("unexpected initial rebuild: %s", line)
+	}
+	_ = os.WriteFile(filepath.Join(root, "b", "broken.png"), []byte("not a png"), 0o644)
+	if line := next(); !strings.Contains(line, "cached=1 failed=1") || !strings.Contains(line, "b [load]") {
+		t.Fatalf("expected b to fail: %s", line)
+	}
+	_ = os.Remove(filepath.Join(root, "b", "broken.png"))
+	if line := next(); !strings.Contains(line, "built=b cached=1 failed=0") {
+		t.Fatalf("expected b rebuilt: %s", line)
+	}
+}
+
+func TestConfigExplain(t *testing.T) {
+	root := t.TempDir()
+	writePNGAt(t, filepath.Join(root, "chars", "hero", "hero_idle_001.png"))
+	_ = os.WriteFile(filepath.Join(root, "pixelc.json"), []byte(`{"padding":2}`), 0o644)
+	_ = os.WriteFile(filepath.Join(root, "chars", "pixelc.json"), []byte(`{"pivotMode":"bottom-center"}`), 0o644)
+	cfgPath := filepath.Join(t.TempDir(), "cfg.json")
+	_ = os.WriteFile(cfgPath, []byte(`{"fps":15}`), 0o644)
+	cmd := exec.Command(testBinary, "config", "explain", filepath.Join(root, "chars", "hero"), "--root", root, "--config", cfgPath, "--preset", "libgdx")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("config explain failed err=%v out=%s", err, out)
+	}
+	for _, want := range []string{
+		"unit chars/hero",
+		"chain pixelc.json -> chars/pixelc.json",
+		"padding=2 (pixelc.json)",
+		"pivotMode=bottom-center (chars/pixelc.json)",
+		"preset=libgdx (--preset)",
+		"fps=15 (" + cfgPath + ")",
+	} {
+		if !strings.Contains(string(out), want+"\n") {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestConfigStrictParsing(t *testing.T) {
+	input := writeTempPNG(t)
+	for _, tc := range []struct {
+		cfg  string
+		want string
+	}{
+		{"{\n  \"padding\": 1,\n  \"pading\": 4\n}", `cfg.json:3:3: unknown key "pading"`},
+		{`{"rules":[{"match":"a","pivot":"center"}]}`, `cfg.json:1:24: unknown key "rules[0].pivot"`},
+		{`{"fps":"fast"}`, `cfg.json:1:8: fps: cannot use string as integer`},
+		{`{"connectivity":0}`, "connectivity must be 4 or 8"},
+	} {
+		cfgPath := filepath.Join(t.TempDir(), "cfg.json")
+		_ = os.WriteFile(cfgPath, []byte(tc.cfg), 0o644)
+		out, err := exec.Command(testBinary, "compile", input, "--out", t.TempDir(), "--config", cfgPath).CombinedOutput()
+		if err == nil || !strings.Contains(string(out), tc.want) {
+			t.Fatalf("%s: expected %q got err=%v out=%s", tc.cfg, tc.want, err, out)
+		}
+	}
+
+	cfgPath := filepath.Join(t.TempDir(), "cfg.json")
+	_ = os.WriteFile(cfgPath, []byte(`{"connectivity":null,"preset":null,"fps":null}`), 0o644)
+	if out, err := exec.Command(testBinary, "compile", input, "--out", t.TempDir(), "--config", cfgPath).CombinedOutput(); err != nil {
+		t.Fatalf("null values should fall back to defaults err=%v out=%s", err, out)
+	}
+}
+
+func TestConfigSchema(t *testing.T) {
+	out, err := exec.Command(testBinary, "config", "schema").Output()
+	if err != nil {
+		t.Fatalf("config schema failed: %v", err)
+	}
+	var schema struct {
+		Title      string                     `json:"title"`
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(out, &schema); err != nil {
+		t.Fatalf("schema is not JSON: %v\n%s", err, out)
+	}
+	if schema.Title != "pixelc.json" || schema.Properties["padding"] == nil {
+		t.Fatalf("unexpected schema: %s", out)
+	}
+}
+
+func TestCompileConfigRulesInReport(t *testing.T) {
+	input := writeTempPNG(t)
+	cfgPath := filepath.Join(t.TempDir(), "cfg.json")
+	_ = os.WriteFile(cfgPath, []byte(`{"rules":[{"match":"sprite_*","pivotMode":"bottom-center"}]}`), 0o644)
+	outDir := filepath.Join(t.TempDir(), "out")
+	cmd := exec.Command(testBinary, "compile", input, "--out", outDir, "--config", cfgPath, "--report")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("compile failed err=%v out=%s", err, out)
+	}
+	data, _ := os.ReadFile(filepath.Join(outDir, "report.json"))
+	if !strings.Contains(string(data), `"sprite_rules":{"sprite_0000":"sprite_*"}`) {
+		t.Fatalf("rule not recorded in report: %s", data)
+	}
+}
+
+func TestCompileNamedAtlases(t *testing.T) {
+	input := t.TempDir()
+	writePNGAt(t, filepath.Join(input, "hero_idle_001.png"))
+	writePNGAt(t, filepath.Join(input, "ui_button.png"))
+	cfgPath := filepath.Join(t.TempDir(), "cfg.json")
+	_ = os.WriteFile(cfgPath, []byte(`{"atlases":[{"name":"ui","match":["ui_*"],"padding":2}]}`), 0o644)
+	outDir := filepath.Join(t.TempDir(), "out")
+	cmd := exec.Command(testBinary, "compile", input, "--out", outDir, "--config", cfgPath)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("compile failed err=%v out=%s", err, out)
+	}
+	if !strings.Contains(string(out), "sprites=2 ") || !strings.Contains(string(out), "atlases=ui:") || !strings.Contains(string(out), "wrote=atlas.png,atlas.json,ui.json,ui.png") {
+		t.Fatalf("unexpected output: %s", out)
+	}
+	assertExists(t, filepath.Join(outDir, "ui.png"))
+
+	_ = os.WriteFile(cfgPath, []byte(`{"atlases":[{"name":"atlas","match":["*"]}]}`), 0o644)
+	cmd = exec.Command(testBinary, "compile", input, "--out", outDir, "--config", cfgPath)
+	if out, err := cmd.CombinedOutput(); err == nil || !strings.Contains(string(out), "reserved") {
+		t.Fatalf("expected reserved atlas name to be rejected err=%v out=%s", err, out)
+	}
+}
+
+func TestCompileScales(t *testing.T) {
+	input := writeTempPNG(t)
+	outDir := filepath.Join(t.TempDir(), "out")
+	cmd := exec.Command(testBinary, "compile", input, "--out", outDir, "--scales", "2,4")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("compile failed err=%v out=%s", err, out)
+	}
+	if !strings.Contains(string(out), "wrote=atlas.png,atlas.json,atlas@2x.json,atlas@2x.png,atlas@4x.json,atlas@4x.png") {
+		t.Fatalf("unexpected output: %s", out)
+	}
+	assertExists(t, filepath.Join(outDir, "atlas@4x.png"))
+
+	cmd = exec.Command(testBinary, "compile", input, "--out", outDir, "--scales", "2,x")
+	if out, err := cmd.CombinedOutput(); err == nil || !strings.Contains(string(out), `invalid scale "x"`) {
+		t.Fatalf("expected invalid scale to be rejected err=%v out=%s", err, out)
+	}
+
+	cmd = exec.Command(testBinary, "compile", input, "--out", outDir, "--scales", "0.5", "--scale-filter", "nearest")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("compile failed err=%v out=%s", err, out)
+	}
+	cmd = exec.Command(testBinary, "compile", input, "--out", outDir, "--scales", "0.5", "--scale-filter", "bilinear")
+	if out, err := cmd.CombinedOutput(); err == nil || !strings.Contains(string(out), "scale filter must be nearest or area") {
+		t.Fatalf("expected invalid scale filter to be rejected err=%v out=%s", err, out)
+	}
+}
+
+func TestCompileCodegen(t *testing.T) {
+	input := writeTempPNG(t)
+	outDir := filepath.Join(t.TempDir(), "out")
+	cmd := exec.Command(testBinary, "compile", input, "--out", outDir, "--codegen", "csharp,go")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("compile failed err=%v out=%s", err, out)
+	}
+	if !strings.Contains(string(out), "wrote=atlas.png,atlas.json,AtlasSprites.cs,atlas_sprites.go") {
+		t.Fatalf("unexpected output: %s", out)
+	}
+	data, _ := os.ReadFile(filepath.Join(outDir, "atlas_sprites.go"))
+	if !strings.Contains(string(data), `SpriteSprite0000 = "sprite_0000"`) {
+		t.Fatalf("sprite constant missing: %s", data)
+	}
+}
+
+func TestCompileCSSPreset(t *testing.T) {
+	input := writeTempPNG(t)
+	outDir := filepath.Join(t.TempDir(), "out")
+	cmd := exec.Command(testBinary, "compile", input, "--out", outDir, "--preset", "css")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("compile failed err=%v out=%s", err, out)
+	}
+	assertExists(t, filepath.Join(outDir, "atlas.css"))
+	assertExists(t, filepath.Join(outDir, "preview.html"))
+}
+
+func TestCompileEmbedC(t *testing.T) {
+	input := writeTempPNG(t)
+	outDir := filepath.Join(t.TempDir(), "out")
+	cmd := exec.Command(testBinary, "compile", input, "--out", outDir, "--embed", "c")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("compile failed err=%v out=%s", err, out)
+	}
+	png, _ := os.ReadFile(filepath.Join(outDir, "atlas.png"))
+	header, _ := os.ReadFile(filepath.Join(outDir, "atlas_embed.h"))
+	if !strings.Contains(string(header), fmt.Sprintf("pixelc_atlas_png_len = %d;", len(png))) {
+		t.Fatalf("embedded png does not match atlas.png:\n%s", header)
+	}
+}
+
 func writeTempPNG(t *testing.T) string {
 	p := filepath.Join(t.TempDir(), "input.png")
 	writePNGAt(t, p)
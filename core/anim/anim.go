@@ -14,6 +14,7 @@ var framePattern = regexp.MustCompile(`^(?i)(.*?)[\s_-]+([0-9]{1,6})$`)
 
 type Parsed struct {
 	Name      string
+	Prefix    string
 	State     string
 	Index     int
 	Grouped   bool
@@ -33,7 +34,7 @@ func ParseFrameName(filename string) Parsed {
 		return Parsed{Name: base, SpriteRef: base, Grouped: false}
 	}
 	state := tokens[len(tokens)-1]
-	return Parsed{Name: base, State: state, Index: index, Grouped: true, SpriteRef: base}
+	return Parsed{Name: base, Prefix: prefix, State: state, Index: index, Grouped: true, SpriteRef: base}
 }
 
 func BuildAnimations(spriteNames []string, fps int) ([]model.Animation, []string, error) {
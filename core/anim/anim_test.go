@@ -4,18 +4,19 @@ import "testing"
 
 func TestParseFrameName(t *testing.T) {
 	cases := map[string]struct {
+		prefix  string
 		state   string
 		idx     int
 		grouped bool
 	}{
-		"player_idle_01.png":   {"idle", 1, true},
-		"player-run-12.png":    {"run", 12, true},
-		"enemy attack 003.png": {"attack", 3, true},
-		"icon.png":             {"", 0, false},
+		"player_idle_01.png":   {"player_idle", "idle", 1, true},
+		"player-run-12.png":    {"player-run", "run", 12, true},
+		"enemy attack 003.png": {"enemy attack", "attack", 3, true},
+		"icon.png":             {"", "", 0, false},
 	}
 	for in, want := range cases {
 		got := ParseFrameName(in)
-		if got.Grouped != want.grouped || got.State != want.state || got.Index != want.idx {
+		if got.Grouped != want.grouped || got.Prefix != want.prefix || got.State != want.state || got.Index != want.idx {
 			t.Fatalf("parse %s got %+v", in, got)
 		}
 	}
@@ -0,0 +1,309 @@
+package collider
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"pixelc/pkg/model"
+)
+
+type edge struct {
+	x0 int
+	y0 int
+	x1 int
+	y1 int
+}
+
+// Build traces the outer alpha outlines of img, simplifies them with the
+// given tolerance and optionally splits them into convex parts.
+func Build(img *image.RGBA, tolerance float64, convex bool) ([][]model.Point, error) {
+	if img == nil {
+		return nil, fmt.Errorf("sprite image is nil")
+	}
+	polys := make([][]model.Point, 0)
+	for _, c := range Trace(img) {
+		if signedArea(c) <= 0 {
+			continue // holes
+		}
+		c = Simplify(c, tolerance)
+		if len(c) < 3 {
+			continue
+		}
+		if convex {
+			polys = append(polys, Decompose(c)...)
+		} else {
+			polys = append(polys, c)
+		}
+	}
+	return polys, nil
+}
+
+// Trace walks the boundary between opaque and transparent pixels on the
+// pixel-corner lattice (marching squares on a binary alpha grid). Outer
+// contours come back with positive signed area, holes with negative.
+// Diagonally touching pixels stay separate, matching 4-connectivity.
+func Trace(img *image.RGBA) [][]model.Point {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	opaque := func(x, y int) bool {
+		return x >= 0 && y >= 0 && x < w && y < h && img.RGBAAt(b.Min.X+x, b.Min.Y+y).A > 0
+	}
+
+	edges := make([]edge, 0)
+	outgoing := map[[2]int][]int{}
+	add := func(e edge) {
+		key := [2]int{e.x0, e.y0}
+		outgoing[key] = append(outgoing[key], len(edges))
+		edges = append(edges, e)
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if !opaque(x, y) {
+				continue
+			}
+			if !opaque(x, y-1) {
+				add(edge{x, y, x + 1, y})
+			}
+			if !opaque(x+1, y) {
+				add(edge{x + 1, y, x + 1, y + 1})
+			}
+			if !opaque(x, y+1) {
+				add(edge{x + 1, y + 1, x, y + 1})
+			}
+			if !opaque(x-1, y) {
+				add(edge{x, y + 1, x, y})
+			}
+		}
+	}
+
+	used := make([]bool, len(edges))
+	contours := make([][]model.Point, 0)
+	for start := range edges {
+		if used[start] {
+			continue
+		}
+		pts := make([]model.Point, 0)
+		for cur := start; cur >= 0 && !used[cur]; {
+			used[cur] = true
+			e := edges[cur]
+			pts = append(pts, model.Point{X: float64(e.x0), Y: float64(e.y0)})
+			next, bestTurn := -1, math.MinInt
+			for _, cand := range outgoing[[2]int{e.x1, e.y1}] {
+				if used[cand] {
+					continue
+				}
+				// prefer the right-most turn so the walk hugs the current pixel
+				c := edges[cand]
+				turn := (e.x1-e.x0)*(c.y1-c.y0) - (e.y1-e.y0)*(c.x1-c.x0)
+				if turn > bestTurn {
+					next, bestTurn = cand, turn
+				}
+			}
+			cur = next
+		}
+		contours = append(contours, removeCollinear(pts))
+	}
+	return contours
+}
+
+// Simplify applies Douglas-Peucker to a closed polygon. A tolerance of zero
+// returns the polygon unchanged.
+func Simplify(poly []model.Point, tolerance float64) []model.Point {
+	out := append([]model.Point(nil), poly...)
+	if tolerance <= 0 || len(poly) <= 3 {
+		return out
+	}
+	far, farDist := 0, -1.0
+	for i, p := range poly {
+		if d := math.Hypot(p.X-poly[0].X, p.Y-poly[0].Y); d > farDist {
+			far, farDist = i, d
+		}
+	}
+	closed := append(append([]model.Point(nil), poly[far:]...), poly[0])
+	a := douglasPeucker(poly[:far+1], tolerance)
+	b := douglasPeucker(closed, tolerance)
+	out = append(a[:len(a)-1], b[:len(b)-1]...)
+	if len(out) < 3 {
+		return append([]model.Point(nil), poly...)
+	}
+	return out
+}
+
+func douglasPeucker(pts []model.Point, tolerance float64) []model.Point {
+	if len(pts) <= 2 {
+		return append([]model.Point(nil), pts...)
+	}
+	first, last := pts[0], pts[len(pts)-1]
+	idx, maxDist := 0, -1.0
+	for i := 1; i < len(pts)-1; i++ {
+		if d := segmentDistance(pts[i], first, last); d > maxDist {
+			idx, maxDist = i, d
+		}
+	}
+	if maxDist <= tolerance {
+		return []model.Point{first, last}
+	}
+	left := douglasPeucker(pts[:idx+1], tolerance)
+	right := douglasPeucker(pts[idx:], tolerance)
+	return append(left[:len(left)-1], right...)
+}
+
+// Decompose splits a simple polygon into convex parts by ear-clipping it
+// into triangles and merging neighbours while they stay convex
+// (Hertel-Mehlhorn).
+func Decompose(poly []model.Point) [][]model.Point {
+	sign := 1.0
+	if signedArea(poly) < 0 {
+		sign = -1
+	}
+	parts := make([][]int, 0)
+	for _, t := range Triangulate(poly) {
+		parts = append(parts, []int{t[0], t[1], t[2]})
+	}
+	for merged := true; merged; {
+		merged = false
+		for i := 0; i < len(parts) && !merged; i++ {
+			for j := i + 1; j < len(parts) && !merged; j++ {
+				m, ok := mergeParts(parts[i], parts[j])
+				if !ok || !isConvex(poly, m, sign) {
+					continue
+				}
+				parts[i] = m
+				parts = append(parts[:j], parts[j+1:]...)
+				merged = true
+			}
+		}
+	}
+	out := make([][]model.Point, 0, len(parts))
+	for _, part := range parts {
+		pts := make([]model.Point, 0, len(part))
+		for _, i := range part {
+			pts = append(pts, poly[i])
+		}
+		out = append(out, pts)
+	}
+	return out
+}
+
+// Triangulate ear-clips a simple polygon and returns triangles as indices
+// into poly with the polygon's winding.
+func Triangulate(poly []model.Point) [][3]int {
+	if len(poly) < 3 {
+		return nil
+	}
+	sign := 1.0
+	if signedArea(poly) < 0 {
+		sign = -1
+	}
+	idx := make([]int, len(poly))
+	for i := range idx {
+		idx[i] = i
+	}
+	tris := make([][3]int, 0, len(poly)-2)
+	for len(idx) > 3 {
+		m := len(idx)
+		ear := -1
+		for k := 0; k < m && ear < 0; k++ {
+			a, b, c := poly[idx[(k+m-1)%m]], poly[idx[k]], poly[idx[(k+1)%m]]
+			if cross(a, b, c)*sign <= 0 {
+				continue
+			}
+			inside := false
+			for _, o := range idx {
+				p := poly[o]
+				if p == a || p == b || p == c {
+					continue
+				}
+				if inTriangle(p, a, b, c, sign) {
+					inside = true
+					break
+				}
+			}
+			if !inside {
+				ear = k
+			}
+		}
+		if ear < 0 {
+			ear = 0 // degenerate input; clip anyway to guarantee progress
+		}
+		t := [3]int{idx[(ear+m-1)%m], idx[ear], idx[(ear+1)%m]}
+		if cross(poly[t[0]], poly[t[1]], poly[t[2]]) != 0 {
+			tris = append(tris, t)
+		}
+		idx = append(idx[:ear], idx[ear+1:]...)
+	}
+	if cross(poly[idx[0]], poly[idx[1]], poly[idx[2]]) != 0 {
+		tris = append(tris, [3]int{idx[0], idx[1], idx[2]})
+	}
+	return tris
+}
+
+// mergeParts joins two index rings sharing an edge (u,v in a, v,u in b).
+func mergeParts(a, b []int) ([]int, bool) {
+	for i := range a {
+		u, v := a[i], a[(i+1)%len(a)]
+		for j := range b {
+			if b[j] != v || b[(j+1)%len(b)] != u {
+				continue
+			}
+			out := make([]int, 0, len(a)+len(b)-2)
+			for k := 0; k < len(a); k++ {
+				out = append(out, a[(i+1+k)%len(a)])
+			}
+			for k := 2; k < len(b); k++ {
+				out = append(out, b[(j+k)%len(b)])
+			}
+			return out, true
+		}
+	}
+	return nil, false
+}
+
+func isConvex(poly []model.Point, ring []int, sign float64) bool {
+	n := len(ring)
+	for i := range ring {
+		if cross(poly[ring[i]], poly[ring[(i+1)%n]], poly[ring[(i+2)%n]])*sign < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func removeCollinear(pts []model.Point) []model.Point {
+	out := make([]model.Point, 0, len(pts))
+	n := len(pts)
+	for i := range pts {
+		if cross(pts[(i+n-1)%n], pts[i], pts[(i+1)%n]) != 0 {
+			out = append(out, pts[i])
+		}
+	}
+	return out
+}
+
+func signedArea(poly []model.Point) float64 {
+	sum := 0.0
+	for i := range poly {
+		p, q := poly[i], poly[(i+1)%len(poly)]
+		sum += p.X*q.Y - q.X*p.Y
+	}
+	return sum / 2
+}
+
+func cross(a, b, c model.Point) float64 {
+	return (b.X-a.X)*(c.Y-b.Y) - (b.Y-a.Y)*(c.X-b.X)
+}
+
+func inTriangle(p, a, b, c model.Point, sign float64) bool {
+	return cross(a, b, p)*sign >= 0 && cross(b, c, p)*sign >= 0 && cross(c, a, p)*sign >= 0
+}
+
+func segmentDistance(p, a, b model.Point) float64 {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	if dx == 0 && dy == 0 {
+		return math.Hypot(p.X-a.X, p.Y-a.Y)
+	}
+	t := ((p.X-a.X)*dx + (p.Y-a.Y)*dy) / (dx*dx + dy*dy)
+	t = math.Max(0, math.Min(1, t))
+	return math.Hypot(p.X-(a.X+t*dx), p.Y-(a.Y+t*dy))
+}
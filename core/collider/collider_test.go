@@ -0,0 +1,103 @@
+package collider
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"pixelc/pkg/model"
+)
+
+func TestTraceSquareAndHole(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 5, 5))
+	fill(img, 1, 1, 4, 4)
+	img.SetRGBA(2, 2, color.RGBA{})
+
+	contours := Trace(img)
+	if len(contours) != 2 {
+		t.Fatalf("expected outer and hole contours, got %d", len(contours))
+	}
+	if a := signedArea(contours[0]); a != 9 {
+		t.Fatalf("outer area got %v want 9", a)
+	}
+	if a := signedArea(contours[1]); a != -1 {
+		t.Fatalf("hole area got %v want -1", a)
+	}
+	if len(contours[0]) != 4 {
+		t.Fatalf("collinear points not removed: %v", contours[0])
+	}
+}
+
+func TestTraceKeepsDiagonalPixelsSeparate(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.SetRGBA(0, 0, color.RGBA{A: 255})
+	img.SetRGBA(1, 1, color.RGBA{A: 255})
+	contours := Trace(img)
+	if len(contours) != 2 {
+		t.Fatalf("expected 2 contours, got %d: %v", len(contours), contours)
+	}
+}
+
+func TestSimplify(t *testing.T) {
+	// staircase diagonal collapses to a triangle with tolerance 1
+	img := image.NewRGBA(image.Rect(0, 0, 6, 6))
+	for y := 0; y < 6; y++ {
+		fill(img, 0, y, y+1, y+1)
+	}
+	outline := Trace(img)[0]
+	simple := Simplify(outline, 1)
+	if len(simple) >= len(outline) || len(simple) < 3 {
+		t.Fatalf("expected simplification, got %d from %d", len(simple), len(outline))
+	}
+	if got := Simplify(outline, 0); len(got) != len(outline) {
+		t.Fatalf("zero tolerance must keep all points")
+	}
+}
+
+func TestDecomposeLShape(t *testing.T) {
+	l := []model.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 2}, {X: 2, Y: 2}, {X: 2, Y: 3}, {X: 0, Y: 3}}
+	parts := Decompose(l)
+	if len(parts) < 2 {
+		t.Fatalf("L shape must split into at least 2 convex parts, got %d", len(parts))
+	}
+	total := 0.0
+	for _, p := range parts {
+		if !isConvex(p, ring(len(p)), 1) {
+			t.Fatalf("part is not convex: %v", p)
+		}
+		total += signedArea(p)
+	}
+	if math.Abs(total-signedArea(l)) > 1e-9 {
+		t.Fatalf("parts area %v does not match polygon area %v", total, signedArea(l))
+	}
+}
+
+func TestBuildSkipsHoles(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 5, 5))
+	fill(img, 0, 0, 5, 5)
+	img.SetRGBA(2, 2, color.RGBA{})
+	polys, err := Build(img, 0, true)
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	if len(polys) != 1 || len(polys[0]) != 4 {
+		t.Fatalf("expected a single convex square, got %v", polys)
+	}
+}
+
+func fill(img *image.RGBA, x0, y0, x1, y1 int) {
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			img.SetRGBA(x, y, color.RGBA{A: 255})
+		}
+	}
+}
+
+func ring(n int) []int {
+	out := make([]int, n)
+	for i := range out {
+		out[i] = i
+	}
+	return out
+}
@@ -0,0 +1,155 @@
+package compiler
+
+import (
+	"encoding/json"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pixelc/pkg/model"
+)
+
+func atlasTree(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	mkpng(t, filepath.Join(root, "hero_idle_001.png"), color.RGBA{R: 255, A: 255})
+	mkpng(t, filepath.Join(root, "ui_button.png"), color.RGBA{G: 255, A: 255})
+	mkpng(t, filepath.Join(root, "ui_panel.png"), color.RGBA{G: 255, A: 255})
+	mkpng(t, filepath.Join(root, "fx", "spark_001.png"), color.RGBA{B: 255, A: 255})
+	return root
+}
+
+func TestCompileNamedAtlases(t *testing.T) {
+	root := atlasTree(t)
+	padding := 4
+	cfg := model.Config{Connectivity: 4, Padding: 1, PivotMode: "center", Preset: "unity,css", FPS: 12, UnityMeta: true, Atlases: []model.AtlasGroup{
+		{Name: "ui", Match: []string{"ui_*"}, Padding: &padding, PivotMode: "top-left"},
+		{Name: "fx", Folder: "fx"},
+	}}
+	b, err := CompileBuild(root, cfg)
+	if err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+	want := "atlas.png,atlas.json,atlas.css,preview.html,atlas.png.meta,ui.json,ui.css,ui.preview.html,ui.png.meta,ui.png,fx.json,fx.css,fx.preview.html,fx.png.meta,fx.png"
+	if got := strings.Join(FileNames(b.Image, b.Files), ","); got != want {
+		t.Fatalf("unexpected files %s", got)
+	}
+	if len(b.Atlas.Sprites) != 1 || b.Atlas.Sprites[0].Sprite.Name != "hero_idle_001" {
+		t.Fatalf("default atlas holds %+v", b.Atlas.Sprites)
+	}
+	if len(b.Named) != 2 || b.Named[0].Name != "ui" || b.Named[1].Name != "fx" || b.SpriteCount() != 4 {
+		t.Fatalf("unexpected named atlases %+v", b.Named)
+	}
+	ui := b.Named[0].Atlas
+	if len(ui.Sprites) != 2 || ui.Sprites[0].AtlasX != 4 || ui.Sprites[0].Sprite.PivotY != 0 {
+		t.Fatalf("ui settings not applied: %+v", ui.Sprites)
+	}
+	if fx := b.Named[1].Atlas; len(fx.Sprites) != 1 || fx.Sprites[0].Sprite.Name != "spark_001" || fx.Sprites[0].AtlasX != 1 {
+		t.Fatalf("fx folder not routed: %+v", fx.Sprites)
+	}
+	byName := map[string][]byte{}
+	for _, f := range b.Files {
+		byName[f.Name] = f.Data
+	}
+	if !strings.Contains(string(byName["ui.json"]), `"ui.png"`) || !strings.Contains(string(byName["ui.preview.html"]), "ui.css") {
+		t.Fatalf("ui metadata does not reference its own files:\n%s", byName["ui.json"])
+	}
+	if string(byName["ui.png.meta"]) == string(byName["fx.png.meta"]) {
+		t.Fatalf("named atlases share a unity meta")
+	}
+}
+
+func TestCompileNamedAtlasesOnly(t *testing.T) {
+	root := t.TempDir()
+	mkpng(t, filepath.Join(root, "ui_button.png"), color.RGBA{G: 255, A: 255})
+	cfg := model.Config{Connectivity: 4, PivotMode: "center", Preset: "unity", Atlases: []model.AtlasGroup{{Name: "ui", Match: []string{"*"}}}}
+	b, err := CompileBuild(root, cfg)
+	if err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+	if b.Image != nil {
+		t.Fatalf("expected no default atlas")
+	}
+	out := t.TempDir()
+	if err := WriteFiles(out, b.Image, b.Files); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(out, "atlas.png")); !os.IsNotExist(err) {
+		t.Fatalf("empty default atlas written")
+	}
+	if got := strings.Join(FileNames(b.Image, b.Files), ","); got != "ui.json,ui.png" {
+		t.Fatalf("unexpected files %s", got)
+	}
+	if _, err := WriteSingleReport(out, "u", b); err != nil {
+		t.Fatalf("report failed: %v", err)
+	}
+}
+
+func TestCompileBatchNamedAtlases(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "in")
+	mkpng(t, filepath.Join(root, "level", "hero_idle_001.png"), color.RGBA{R: 255, A: 255})
+	mkpng(t, filepath.Join(root, "level", "fx", "spark_001.png"), color.RGBA{B: 255, A: 255})
+	cfg := model.Config{Connectivity: 4, PivotMode: "center", Preset: "unity", Atlases: []model.AtlasGroup{{Name: "fx", Folder: "fx"}}}
+	opts := BatchOptions{OutDir: filepath.Join(t.TempDir(), "out"), WriteReport: true}
+	res, err := CompileBatch(root, cfg, opts)
+	if err != nil {
+		t.Fatalf("batch compile failed: %v", err)
+	}
+	if len(res.Units) != 1 || res.Units[0].UnitName != "level" {
+		t.Fatalf("group folder compiled as its own unit: %+v", res.Units)
+	}
+	var rep struct {
+		Atlases []struct {
+			Name        string `json:"name"`
+			SpriteCount int    `json:"sprite_count"`
+		} `json:"atlases"`
+	}
+	if err := json.Unmarshal(res.Units[0].Report, &rep); err != nil || len(rep.Atlases) != 1 || rep.Atlases[0].Name != "fx" || rep.Atlases[0].SpriteCount != 1 {
+		t.Fatalf("report atlases wrong (%v): %s", err, res.Units[0].Report)
+	}
+	assertFile := func(name string) {
+		t.Helper()
+		if _, err := os.Stat(filepath.Join(opts.OutDir, "level", name)); err != nil {
+			t.Fatalf("missing %s: %v", name, err)
+		}
+	}
+	assertFile("fx.png")
+	assertFile("fx.json")
+
+	mkpng(t, filepath.Join(root, "level", "fx", "spark_002.png"), color.RGBA{B: 255, A: 255})
+	res, err = CompileBatch(root, cfg, opts)
+	if err != nil {
+		t.Fatalf("rebuild failed: %v", err)
+	}
+	if res.Units[0].Cached {
+		t.Fatalf("change in a group folder served from cache")
+	}
+}
+
+func TestCompileBatchGroupFolderOptional(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "in")
+	mkpng(t, filepath.Join(root, "u1", "hero_idle_001.png"), color.RGBA{R: 255, A: 255})
+	mkpng(t, filepath.Join(root, "u1", "fx", "spark_001.png"), color.RGBA{B: 255, A: 255})
+	mkpng(t, filepath.Join(root, "u2", "enemy_idle_001.png"), color.RGBA{G: 255, A: 255})
+	cfg := model.Config{Connectivity: 4, PivotMode: "center", Preset: "unity", Atlases: []model.AtlasGroup{{Name: "fx", Folder: "fx"}}}
+	opts := BatchOptions{OutDir: filepath.Join(t.TempDir(), "out")}
+	res, err := CompileBatch(root, cfg, opts)
+	if err != nil {
+		t.Fatalf("batch compile failed: %v", err)
+	}
+	if len(res.Units) != 2 || res.Units[0].UnitName != "u1" || res.Units[1].UnitName != "u2" {
+		t.Fatalf("unexpected units %+v", res.Units)
+	}
+	if _, err := os.Stat(filepath.Join(opts.OutDir, "u1", "fx.png")); err != nil {
+		t.Fatalf("u1 fx atlas missing: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(opts.OutDir, "u2", "fx.png")); !os.IsNotExist(err) {
+		t.Fatalf("u2 has no fx folder but wrote fx.png")
+	}
+	res, err = CompileBatch(root, cfg, opts)
+	if err != nil || !res.Units[1].Cached {
+		t.Fatalf("u2 not cached on rebuild (err=%v)", err)
+	}
+}
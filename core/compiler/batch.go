@@ -2,14 +2,18 @@ package compiler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"image"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
 
 	"pixelc/core/anim"
+	"pixelc/core/exporter"
 	"pixelc/internal/imageutil"
 	"pixelc/internal/testutil"
 	"pixelc/pkg/model"
@@ -20,141 +24,340 @@ type BatchOptions struct {
 	DryRun         bool
 	WriteReport    bool
 	OutDir         string
+	Force          bool // ignore the cache manifest and rebuild every unit
+	Jobs           int  // units compiled concurrently; 0 means GOMAXPROCS
+	KeepGoing      bool // compile every unit and collect failures instead of stopping at the first
 }
 
 type BatchResult struct {
-	Units []UnitResult `json:"units"`
+	Units    []UnitResult  `json:"units"`
+	Failures []UnitFailure `json:"failures,omitempty"`
+}
+
+// UnitFailure is a unit that failed under BatchOptions.KeepGoing. Stage is
+// one of the Stage* constants, or empty when the failure has no stage.
+type UnitFailure struct {
+	UnitName string `json:"unit_name"`
+	Stage    string `json:"stage"`
+	Message  string `json:"message"`
 }
 
 type UnitResult struct {
-	UnitName string      `json:"unit_name"`
-	OutDir   string      `json:"out_dir"`
-	Atlas    model.Atlas `json:"atlas"`
-	JSON     []byte      `json:"-"`
-	Report   []byte      `json:"report,omitempty"`
+	UnitName string          `json:"unit_name"`
+	OutDir   string          `json:"out_dir"`
+	Atlas    model.Atlas     `json:"atlas"`
+	JSON     []byte          `json:"-"`
+	Files    []exporter.File `json:"-"`
+	Report   []byte          `json:"report,omitempty"`
+	Cached   bool            `json:"cached,omitempty"` // outputs reused from an earlier run; Atlas is empty
 }
 
 type reportJSON struct {
-	UnitName       string `json:"unit_name"`
+	UnitName       string            `json:"unit_name"`
+	SpriteCount    int               `json:"sprite_count"`
+	AtlasWidth     int               `json:"atlas_width"`
+	AtlasHeight    int               `json:"atlas_height"`
+	Animations     int               `json:"animations_count"`
+	AtlasPngSHA256 string            `json:"atlas_png_sha256"`
+	AtlasJSONSHA   string            `json:"atlas_json_sha256"`
+	SpriteRules    map[string]string `json:"sprite_rules,omitempty"`
+	ConfigChain    []string          `json:"config_chain,omitempty"` // pixelc.json files merged for the unit, outermost first
+	Atlases        []atlasReportJSON `json:"atlases,omitempty"`
+}
+
+type atlasReportJSON struct {
+	Name           string `json:"name"`
 	SpriteCount    int    `json:"sprite_count"`
 	AtlasWidth     int    `json:"atlas_width"`
 	AtlasHeight    int    `json:"atlas_height"`
-	Animations     int    `json:"animations_count"`
 	AtlasPngSHA256 string `json:"atlas_png_sha256"`
-	AtlasJSONSHA   string `json:"atlas_json_sha256"`
 }
 
 func CompileBatch(inputPath string, cfg model.Config, opts BatchOptions) (*BatchResult, error) {
-	units, err := discoverUnits(inputPath, opts.IgnorePatterns)
+	if err := ValidateConfig(cfg); err != nil {
+		return nil, err
+	}
+	units, err := discoverUnits(inputPath, cfg, opts.IgnorePatterns)
 	if err != nil {
 		return nil, err
 	}
+	cache := loadCacheManifest(opts.OutDir)
+	jobs := opts.Jobs
+	if jobs <= 0 {
+		jobs = runtime.GOMAXPROCS(0)
+	}
+	jobs = min(jobs, len(units))
+
+	// Units are compiled in parallel but written in unit order, and unless
+	// KeepGoing the build stops at the first failure. Nothing after a failed
+	// unit is written and the error reported matches a sequential build.
+	// Each compiled unit holds a slot until it is written, so jobs also
+	// bounds how many units' images are in memory.
+	pending := make([]pendingUnit, len(units))
+	errs := make([]error, len(units))
+	done := make([]chan struct{}, len(units))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	slots := make(chan struct{}, jobs)
+	stop := make(chan struct{})
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				pending[i], errs[i] = compileBatchUnit(inputPath, units[i], opts, cache)
+				close(done[i])
+			}
+		}()
+	}
+	go func() {
+		defer close(next)
+		for i := range units {
+			select {
+			case slots <- struct{}{}:
+			case <-stop:
+				return
+			}
+			next <- i
+		}
+	}()
 	result := &BatchResult{Units: make([]UnitResult, 0, len(units))}
-	for _, rel := range units {
-		unitPath := filepath.Join(inputPath, rel)
-		atlas, atlasImg, presetJSON, err := Compile(unitPath, cfg)
-		if err != nil {
-			return nil, fmt.Errorf("compile unit %s: %w", rel, err)
+	manifest := cacheManifest{Version: cacheManifestVersion, Units: make(map[string]cacheEntry, len(units))}
+	for i, u := range units {
+		<-done[i]
+		rel := u.rel
+		err := errs[i]
+		if err == nil && !opts.DryRun {
+			err = pending[i].write()
 		}
-		outDir := filepath.Join(opts.OutDir, rel)
-		if !opts.DryRun {
-			if err := WriteOutputs(outDir, atlasImg, presetJSON); err != nil {
-				return nil, err
+		pending[i].build = nil
+		<-slots
+		if err != nil {
+			if !opts.KeepGoing {
+				close(stop)
+				wg.Wait()
+				return nil, fmt.Errorf("compile unit %s: %w", rel, err)
 			}
+			result.Failures = append(result.Failures, UnitFailure{UnitName: rel, Stage: ErrorStage(err), Message: err.Error()})
+			continue
 		}
-		unit := UnitResult{UnitName: rel, OutDir: outDir, Atlas: *atlas, JSON: presetJSON}
-		if opts.WriteReport {
-			rep, err := buildUnitReport(rel, *atlas, atlasImg, presetJSON)
-			if err != nil {
-				return nil, err
-			}
-			unit.Report = rep
-			if !opts.DryRun {
-				if err := os.MkdirAll(outDir, 0o755); err != nil {
-					return nil, err
-				}
-				if err := os.WriteFile(filepath.Join(outDir, "report.json"), rep, 0o644); err != nil {
-					return nil, err
-				}
-			}
+		result.Units = append(result.Units, pending[i].unit)
+		manifest.Units[rel] = pending[i].entry
+	}
+	wg.Wait()
+	if !opts.DryRun {
+		if err := manifest.write(opts.OutDir); err != nil {
+			return nil, fmt.Errorf("write cache manifest: %w", err)
 		}
-		result.Units = append(result.Units, unit)
 	}
 	return result, nil
 }
 
-func buildUnitReport(unitName string, atlas model.Atlas, atlasImg *image.RGBA, presetJSON []byte) ([]byte, error) {
+// pendingUnit is a compiled batch unit whose outputs are not written yet.
+// build is nil for units served from the cache.
+type pendingUnit struct {
+	unit  UnitResult
+	entry cacheEntry
+	build *Build
+}
+
+func compileBatchUnit(inputPath string, u discoveredUnit, opts BatchOptions, cache cacheManifest) (pendingUnit, error) {
+	rel, cfg := u.rel, u.cfg
+	unitPath := filepath.Join(inputPath, rel)
+	outDir := filepath.Join(opts.OutDir, rel)
+	hash, err := unitHash(unitPath, cfg, u.chain)
+	if err != nil {
+		return pendingUnit{}, atStage(StageLoad, err)
+	}
+	if entry, ok := cache.Units[rel]; ok && !opts.Force && entry.fresh(hash, outDir, opts.WriteReport) {
+		unit, err := cachedUnit(rel, outDir, entry, opts.WriteReport)
+		if err != nil {
+			return pendingUnit{}, atStage(StageLoad, err)
+		}
+		return pendingUnit{unit: unit, entry: entry}, nil
+	}
+
+	b, err := compileUnit(unitPath, rel, cfg)
+	if err != nil {
+		return pendingUnit{}, err
+	}
+	unit := UnitResult{UnitName: rel, OutDir: outDir, Atlas: b.Atlas, JSON: b.Files[0].Data, Files: b.Files}
+	if opts.WriteReport {
+		rep, err := buildUnitReport(rel, b, u.chain)
+		if err != nil {
+			return pendingUnit{}, atStage(StageExport, err)
+		}
+		unit.Report = rep
+	}
+	return pendingUnit{unit: unit, entry: cacheEntry{Hash: hash, Files: FileNames(b.Image, b.Files)}, build: b}, nil
+}
+
+// write writes the unit's outputs and its report, if any, to its out dir.
+func (p *pendingUnit) write() error {
+	if p.build == nil {
+		return nil
+	}
+	outDir := p.unit.OutDir
+	if err := WriteFiles(outDir, p.build.Image, p.build.Files); err != nil {
+		return atStage(StageExport, err)
+	}
+	if p.unit.Report == nil {
+		return nil
+	}
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return atStage(StageExport, err)
+	}
+	if err := os.WriteFile(filepath.Join(outDir, "report.json"), p.unit.Report, 0o644); err != nil {
+		return atStage(StageExport, err)
+	}
+	p.entry.Files = append(p.entry.Files, "report.json")
+	return nil
+}
+
+// buildUnitReport describes b. The top-level counts and hashes cover the
+// default atlas; named atlases are listed under atlases.
+func buildUnitReport(unitName string, b *Build, chain []string) ([]byte, error) {
+	atlas := b.Atlas
 	names := make([]string, 0, len(atlas.Sprites))
+	rules := map[string]string{}
 	for _, s := range atlas.Sprites {
 		names = append(names, s.Sprite.Name)
+		if s.Sprite.Rule != "" {
+			rules[s.Sprite.Name] = s.Sprite.Rule
+		}
 	}
 	anims, _, err := anim.BuildAnimations(names, 12)
 	if err != nil {
 		return nil, err
 	}
 	rep := reportJSON{
-		UnitName:       unitName,
-		SpriteCount:    len(atlas.Sprites),
-		AtlasWidth:     atlas.Width,
-		AtlasHeight:    atlas.Height,
-		Animations:     len(anims),
-		AtlasPngSHA256: imageutil.HashRGBA(atlasImg),
-		AtlasJSONSHA:   testutil.HashBytes(presetJSON),
-	}
-	b, err := json.Marshal(rep)
+		UnitName:     unitName,
+		SpriteCount:  len(atlas.Sprites),
+		AtlasWidth:   atlas.Width,
+		AtlasHeight:  atlas.Height,
+		Animations:   len(anims),
+		AtlasJSONSHA: testutil.HashBytes(b.Files[0].Data),
+	}
+	if b.Image != nil {
+		rep.AtlasPngSHA256 = imageutil.HashRGBA(b.Image)
+	}
+	for _, n := range b.Named {
+		rep.Atlases = append(rep.Atlases, atlasReportJSON{
+			Name: n.Name, SpriteCount: len(n.Atlas.Sprites), AtlasWidth: n.Atlas.Width, AtlasHeight: n.Atlas.Height, AtlasPngSHA256: imageutil.HashRGBA(n.Image),
+		})
+	}
+	if len(rules) > 0 {
+		rep.SpriteRules = rules
+	}
+	if len(chain) > 0 {
+		rep.ConfigChain = chain
+	}
+	data, err := json.Marshal(rep)
 	if err != nil {
 		return nil, err
 	}
-	return testutil.CanonicalJSON(b)
+	return testutil.CanonicalJSON(data)
+}
+
+// discoveredUnit is a batch unit with the config merged from the pixelc.json
+// files on its path.
+type discoveredUnit struct {
+	rel   string
+	cfg   model.Config
+	chain []string
 }
 
-func discoverUnits(root string, ignore []string) ([]string, error) {
-	allIgnore := append([]string{".git", "node_modules", "build", "dist"}, ignore...)
-	units := make([]string, 0)
-	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+func discoverUnits(root string, cfg model.Config, ignore []string) ([]discoveredUnit, error) {
+	base := dirState{cfg: cfg}
+	for _, p := range append([]string{".git", "node_modules", "build", "dist"}, ignore...) {
+		base.ignore = append(base.ignore, scopedPattern{pattern: p})
+	}
+	states := map[string]dirState{}
+	claimed := map[string]bool{}
+	units := make([]discoveredUnit, 0)
+	err := filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
-		rel, _ := filepath.Rel(root, path)
+		rel, _ := filepath.Rel(root, p)
+		rel = filepath.ToSlash(rel)
 		if rel == "." {
 			rel = ""
 		}
-		if shouldIgnore(rel, allIgnore) {
-			if d.IsDir() {
+		if !d.IsDir() {
+			return nil
+		}
+		parent := base
+		if rel != "" {
+			if claimed[rel] {
+				return filepath.SkipDir
+			}
+			dir := path.Dir(rel)
+			if dir == "." {
+				dir = ""
+			}
+			parent = states[dir]
+			if parent.ignores(rel) {
 				return filepath.SkipDir
 			}
-			return nil
 		}
-		if !d.IsDir() {
-			return nil
+		state, err := parent.enter(root, rel)
+		if err != nil {
+			return err
 		}
-		entries, err := os.ReadDir(path)
+		states[rel] = state
+		hasPNG, err := dirHasPNG(p)
 		if err != nil {
 			return err
 		}
-		hasPNG := false
-		for _, e := range entries {
-			if !e.IsDir() && strings.EqualFold(filepath.Ext(e.Name()), ".png") {
-				hasPNG = true
-				break
+		// Atlas group folders are compiled as part of this unit, not as
+		// units of their own.
+		for _, g := range state.cfg.Atlases {
+			if g.Folder == "" {
+				continue
+			}
+			claimed[path.Join(rel, g.Folder)] = true
+			if !hasPNG {
+				if hasPNG, err = dirHasPNG(filepath.Join(p, filepath.FromSlash(g.Folder))); errors.Is(err, os.ErrNotExist) {
+					err = nil
+				}
+				if err != nil {
+					return err
+				}
 			}
 		}
 		if hasPNG {
-			if rel == "" {
-				units = append(units, ".")
-			} else {
-				units = append(units, filepath.ToSlash(rel))
+			name := rel
+			if name == "" {
+				name = "."
 			}
+			units = append(units, discoveredUnit{rel: name, cfg: state.cfg, chain: state.chain})
 		}
 		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
-	sort.Strings(units)
+	sort.Slice(units, func(i, j int) bool { return units[i].rel < units[j].rel })
 	return units, nil
 }
 
+func dirHasPNG(dir string) (bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false, err
+	}
+	for _, e := range entries {
+		if !e.IsDir() && strings.EqualFold(filepath.Ext(e.Name()), ".png") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func shouldIgnore(rel string, patterns []string) bool {
 	rel = filepath.ToSlash(rel)
 	for _, p := range patterns {
@@ -1,10 +1,12 @@
 package compiler
 
 import (
+	"fmt"
 	"image"
 	"image/color"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"pixelc/internal/imageutil"
@@ -65,6 +67,28 @@ func TestCompileBatchDryRun(t *testing.T) {
 	}
 }
 
+func TestCompileBatchUnityMetaPerUnitGUID(t *testing.T) {
+	root := t.TempDir()
+	mkpng(t, filepath.Join(root, "a", "hero_idle_001.png"), color.RGBA{R: 255, A: 255})
+	mkpng(t, filepath.Join(root, "b", "hero_idle_001.png"), color.RGBA{R: 255, A: 255})
+	cfg := model.Config{Connectivity: 4, Padding: 1, PivotMode: "center", Preset: "unity", UnityMeta: true}
+	out := filepath.Join(t.TempDir(), "out")
+	if _, err := CompileBatch(root, cfg, BatchOptions{OutDir: out}); err != nil {
+		t.Fatalf("batch compile failed: %v", err)
+	}
+	a, err := os.ReadFile(filepath.Join(out, "a", "atlas.png.meta"))
+	if err != nil {
+		t.Fatalf("missing meta: %v", err)
+	}
+	b, err := os.ReadFile(filepath.Join(out, "b", "atlas.png.meta"))
+	if err != nil {
+		t.Fatalf("missing meta: %v", err)
+	}
+	if strings.SplitN(string(a), "\n", 3)[1] == strings.SplitN(string(b), "\n", 3)[1] {
+		t.Fatalf("units share a guid")
+	}
+}
+
 func mkpng(t *testing.T, path string, c color.RGBA) {
 	t.Helper()
 	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
@@ -77,3 +101,181 @@ func mkpng(t *testing.T, path string, c color.RGBA) {
 		t.Fatal(err)
 	}
 }
+
+func TestCompileBatchCache(t *testing.T) {
+	root := t.TempDir()
+	mkpng(t, filepath.Join(root, "a", "hero_idle_001.png"), color.RGBA{R: 255, A: 255})
+	mkpng(t, filepath.Join(root, "b", "enemy_run_001.png"), color.RGBA{B: 255, A: 255})
+	cfg := model.Config{Connectivity: 4, Padding: 1, PivotMode: "center", Preset: "unity", FPS: 12}
+	out := filepath.Join(t.TempDir(), "out")
+	opts := BatchOptions{OutDir: out, WriteReport: true}
+	cachedUnits := func(res *BatchResult) []string {
+		names := []string{}
+		for _, u := range res.Units {
+			if u.Cached {
+				names = append(names, u.UnitName)
+			}
+		}
+		return names
+	}
+
+	first, err := CompileBatch(root, cfg, opts)
+	if err != nil {
+		t.Fatalf("batch compile failed: %v", err)
+	}
+	if got := cachedUnits(first); len(got) != 0 {
+		t.Fatalf("first run used cache: %v", got)
+	}
+	second, err := CompileBatch(root, cfg, opts)
+	if err != nil {
+		t.Fatalf("batch compile failed: %v", err)
+	}
+	if got := cachedUnits(second); strings.Join(got, ",") != "a,b" {
+		t.Fatalf("expected both units cached got %v", got)
+	}
+	for i := range second.Units {
+		if string(second.Units[i].JSON) != string(first.Units[i].JSON) || string(second.Units[i].Report) != string(first.Units[i].Report) {
+			t.Fatalf("cached unit %s differs from its build", second.Units[i].UnitName)
+		}
+	}
+
+	mkpng(t, filepath.Join(root, "a", "hero_idle_002.png"), color.RGBA{G: 255, A: 255})
+	res, err := CompileBatch(root, cfg, opts)
+	if err != nil {
+		t.Fatalf("batch compile failed: %v", err)
+	}
+	if got := cachedUnits(res); strings.Join(got, ",") != "b" {
+		t.Fatalf("changed input: expected only b cached got %v", got)
+	}
+
+	if err := os.Remove(filepath.Join(out, "b", "atlas.png")); err != nil {
+		t.Fatal(err)
+	}
+	if res, err = CompileBatch(root, cfg, opts); err != nil {
+		t.Fatalf("batch compile failed: %v", err)
+	}
+	if got := cachedUnits(res); strings.Join(got, ",") != "a" {
+		t.Fatalf("missing output: expected only a cached got %v", got)
+	}
+
+	cfg.Padding = 2
+	if res, err = CompileBatch(root, cfg, opts); err != nil {
+		t.Fatalf("batch compile failed: %v", err)
+	}
+	if got := cachedUnits(res); len(got) != 0 {
+		t.Fatalf("changed config: expected rebuild got %v", got)
+	}
+
+	opts.Force = true
+	if res, err = CompileBatch(root, cfg, opts); err != nil {
+		t.Fatalf("batch compile failed: %v", err)
+	}
+	if got := cachedUnits(res); len(got) != 0 {
+		t.Fatalf("force: expected rebuild got %v", got)
+	}
+}
+
+func TestCompileBatchJobsMatchSequential(t *testing.T) {
+	root := t.TempDir()
+	colors := []color.RGBA{{R: 255, A: 255}, {G: 255, A: 255}, {B: 255, A: 255}}
+	for i := 0; i < 9; i++ {
+		mkpng(t, filepath.Join(root, fmt.Sprintf("u%d", i), "hero_idle_001.png"), colors[i%3])
+		mkpng(t, filepath.Join(root, fmt.Sprintf("u%d", i), "hero_idle_002.png"), colors[(i+1)%3])
+	}
+	cfg := model.Config{Connectivity: 4, Padding: 1, PivotMode: "center", Preset: "unity,libgdx", FPS: 12, UnityMeta: true}
+	seqOut := filepath.Join(t.TempDir(), "seq")
+	parOut := filepath.Join(t.TempDir(), "par")
+	seq, err := CompileBatch(root, cfg, BatchOptions{OutDir: seqOut, WriteReport: true, Jobs: 1})
+	if err != nil {
+		t.Fatalf("sequential batch failed: %v", err)
+	}
+	par, err := CompileBatch(root, cfg, BatchOptions{OutDir: parOut, WriteReport: true, Jobs: 4})
+	if err != nil {
+		t.Fatalf("parallel batch failed: %v", err)
+	}
+	if len(seq.Units) != len(par.Units) {
+		t.Fatalf("unit count differs: %d vs %d", len(seq.Units), len(par.Units))
+	}
+	for i := range seq.Units {
+		if seq.Units[i].UnitName != par.Units[i].UnitName {
+			t.Fatalf("unit order differs at %d: %s vs %s", i, seq.Units[i].UnitName, par.Units[i].UnitName)
+		}
+		for _, name := range []string{"atlas.png", "atlas.json", "atlas.atlas", "atlas.png.meta", "report.json"} {
+			a, err := os.ReadFile(filepath.Join(seqOut, seq.Units[i].UnitName, name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := os.ReadFile(filepath.Join(parOut, par.Units[i].UnitName, name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(a) != string(b) {
+				t.Fatalf("%s/%s differs between sequential and parallel runs", seq.Units[i].UnitName, name)
+			}
+		}
+	}
+}
+
+func TestCompileBatchJobsFirstError(t *testing.T) {
+	root := t.TempDir()
+	for i := 0; i < 6; i++ {
+		mkpng(t, filepath.Join(root, fmt.Sprintf("u%d", i), "hero_idle_001.png"), color.RGBA{R: 255, A: 255})
+	}
+	for _, bad := range []string{"u2", "u4"} {
+		if err := os.WriteFile(filepath.Join(root, bad, "broken.png"), []byte("not a png"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := model.Config{Connectivity: 4, Padding: 1, PivotMode: "center", Preset: "unity", FPS: 12}
+	for _, jobs := range []int{1, 3} {
+		out := filepath.Join(t.TempDir(), "out")
+		_, err := CompileBatch(root, cfg, BatchOptions{OutDir: out, Jobs: jobs})
+		if err == nil || !strings.Contains(err.Error(), "compile unit u2") {
+			t.Fatalf("jobs=%d: expected the u2 failure got %v", jobs, err)
+		}
+		for i := 0; i < 6; i++ {
+			_, err := os.Stat(filepath.Join(out, fmt.Sprintf("u%d", i), "atlas.json"))
+			if written := err == nil; written != (i < 2) {
+				t.Fatalf("jobs=%d: u%d written=%v", jobs, i, written)
+			}
+		}
+	}
+}
+
+func TestCompileBatchKeepGoing(t *testing.T) {
+	root := t.TempDir()
+	mkpng(t, filepath.Join(root, "a", "hero_idle_001.png"), color.RGBA{R: 255, A: 255})
+	mkpng(t, filepath.Join(root, "b", "hero_idle_001.png"), color.RGBA{R: 255, A: 255})
+	mkpng(t, filepath.Join(root, "c", "hero_idle_001.png"), color.RGBA{R: 255, A: 255})
+	if err := os.WriteFile(filepath.Join(root, "b", "broken.png"), []byte("not a png"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := model.Config{Connectivity: 4, Padding: 1, PivotMode: "center", Preset: "unity", FPS: 12}
+	out := filepath.Join(t.TempDir(), "out")
+
+	if _, err := CompileBatch(root, cfg, BatchOptions{OutDir: out}); err == nil || ErrorStage(err) != StageLoad {
+		t.Fatalf("expected a load-stage error got %v", err)
+	}
+
+	res, err := CompileBatch(root, cfg, BatchOptions{OutDir: out, KeepGoing: true, Jobs: 2})
+	if err != nil {
+		t.Fatalf("keep-going batch failed: %v", err)
+	}
+	if len(res.Units) != 2 || res.Units[0].UnitName != "a" || res.Units[1].UnitName != "c" {
+		t.Fatalf("expected units a and c got %+v", res.Units)
+	}
+	if len(res.Failures) != 1 {
+		t.Fatalf("expected one failure got %+v", res.Failures)
+	}
+	f := res.Failures[0]
+	if f.UnitName != "b" || f.Stage != StageLoad || !strings.Contains(f.Message, "broken.png") {
+		t.Fatalf("unexpected failure %+v", f)
+	}
+	if _, err := os.Stat(filepath.Join(out, "c", "atlas.json")); err != nil {
+		t.Fatalf("unit after the failure not written: %v", err)
+	}
+	manifest := loadCacheManifest(out)
+	if _, ok := manifest.Units["b"]; ok {
+		t.Fatalf("failed unit recorded in the cache manifest")
+	}
+}
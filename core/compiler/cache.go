@@ -0,0 +1,172 @@
+package compiler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"pixelc/core/exporter"
+	"pixelc/internal/version"
+	"pixelc/pkg/model"
+)
+
+// CacheManifestName is the batch cache manifest kept in the output root.
+const CacheManifestName = ".pixelc-cache.json"
+
+const cacheManifestVersion = 1
+
+type cacheManifest struct {
+	Version int                   `json:"version"`
+	Units   map[string]cacheEntry `json:"units"`
+}
+
+type cacheEntry struct {
+	Hash  string   `json:"hash"`
+	Files []string `json:"files"`
+}
+
+// loadCacheManifest returns an empty manifest when none exists or the file
+// cannot be used, so a damaged cache only costs a full rebuild.
+func loadCacheManifest(outDir string) cacheManifest {
+	empty := cacheManifest{Version: cacheManifestVersion, Units: map[string]cacheEntry{}}
+	data, err := os.ReadFile(filepath.Join(outDir, CacheManifestName))
+	if err != nil {
+		return empty
+	}
+	var m cacheManifest
+	if err := json.Unmarshal(data, &m); err != nil || m.Version != cacheManifestVersion || m.Units == nil {
+		return empty
+	}
+	return m
+}
+
+func (m cacheManifest) write(outDir string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(outDir, CacheManifestName), append(data, '\n'), 0o644)
+}
+
+// fresh reports whether entry matches hash and every output it recorded is
+// still on disk.
+func (e cacheEntry) fresh(hash, outDir string, needReport bool) bool {
+	if e.Hash != hash {
+		return false
+	}
+	hasReport := false
+	for _, name := range e.Files {
+		if _, err := os.Stat(filepath.Join(outDir, name)); err != nil {
+			return false
+		}
+		hasReport = hasReport || name == "report.json"
+	}
+	return hasReport || !needReport
+}
+
+// unitHash keys a unit by its PNG inputs and their Aseprite slice files,
+// including those in atlas group folders, the effective config and the
+// pixelc.json files it came from, the pixelc build and any exec: plugin
+// programs.
+func unitHash(unitPath string, cfg model.Config, chain []string) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "pixelc %s %s\n", version.Version, version.Commit)
+	cfgJSON, err := json.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(h, "config %s\n", cfgJSON)
+	fmt.Fprintf(h, "chain %s\n", strings.Join(chain, ","))
+
+	dirs := []string{""}
+	for _, g := range cfg.Atlases {
+		if g.Folder != "" {
+			dirs = append(dirs, g.Folder)
+		}
+	}
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(filepath.Join(unitPath, filepath.FromSlash(dir)))
+		if dir != "" && errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("read folder: %w", err)
+		}
+		names := make([]string, 0)
+		for _, e := range entries {
+			if !e.IsDir() && strings.EqualFold(filepath.Ext(e.Name()), ".png") {
+				names = append(names, path.Join(dir, e.Name()))
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := hashFile(h, "input "+name, filepath.Join(unitPath, filepath.FromSlash(name))); err != nil {
+				return "", err
+			}
+			sidecar := filepath.Join(unitPath, filepath.FromSlash(asepriteSidecar(name)))
+			if err := hashFile(h, "slices "+name, sidecar); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return "", err
+			}
+		}
+	}
+	for _, p := range cfg.Presets() {
+		if bin, ok := strings.CutPrefix(p, exporter.ExecPrefix); ok {
+			resolved, err := exec.LookPath(bin)
+			if err != nil {
+				return "", fmt.Errorf("preset %s: %w", p, err)
+			}
+			if err := hashFile(h, "plugin "+p, resolved); err != nil {
+				return "", err
+			}
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashFile(h io.Writer, label, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	fmt.Fprintf(h, "%s %s\n", label, hex.EncodeToString(sum[:]))
+	return nil
+}
+
+// cachedUnit rebuilds a UnitResult from the outputs of an earlier run. Atlas
+// is left empty since the unit was not packed.
+func cachedUnit(rel, outDir string, entry cacheEntry, withReport bool) (UnitResult, error) {
+	unit := UnitResult{UnitName: rel, OutDir: outDir, Cached: true}
+	for _, name := range entry.Files {
+		if name == "atlas.png" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(outDir, name))
+		if err != nil {
+			return UnitResult{}, err
+		}
+		if name == "report.json" {
+			if withReport {
+				unit.Report = data
+			}
+			continue
+		}
+		unit.Files = append(unit.Files, exporter.File{Name: name, Data: data})
+	}
+	if len(unit.Files) > 0 {
+		unit.JSON = unit.Files[0].Data
+	}
+	return unit, nil
+}
@@ -1,6 +1,7 @@
 package compiler
 
 import (
+	"errors"
 	"fmt"
 	"image"
 	"os"
@@ -8,9 +9,14 @@ import (
 	"sort"
 	"strings"
 
+	"pixelc/core/anim"
+	"pixelc/core/collider"
 	"pixelc/core/exporter"
+	"pixelc/core/mesh"
+	"pixelc/core/nineslice"
 	"pixelc/core/packer"
 	"pixelc/core/pivot"
+	"pixelc/core/scale"
 	"pixelc/core/slicer"
 	"pixelc/core/trim"
 	"pixelc/internal/imageutil"
@@ -19,65 +25,342 @@ import (
 )
 
 func Compile(inputPath string, cfg model.Config) (*model.Atlas, *image.RGBA, []byte, error) {
-	if err := cfg.Validate(); err != nil {
+	atlas, atlasImg, files, err := CompileFiles(inputPath, cfg)
+	if err != nil {
 		return nil, nil, nil, err
 	}
+	return atlas, atlasImg, files[0].Data, nil
+}
+
+// CompileFiles is Compile for presets that write more than one file or a
+// file other than atlas.json. The first file is the primary metadata.
+func CompileFiles(inputPath string, cfg model.Config) (*model.Atlas, *image.RGBA, []exporter.File, error) {
+	b, err := CompileBuild(inputPath, cfg)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return &b.Atlas, b.Image, b.Files, nil
+}
+
+// Build is everything compiled from one input. Image is the default atlas
+// and is nil when cfg.Atlases claimed every sprite. Files holds the metadata
+// of every atlas and the PNGs of the named ones; atlas.png is written from
+// Image.
+type Build struct {
+	Atlas model.Atlas
+	Image *image.RGBA
+	Named []NamedAtlas
+	Files []exporter.File
+}
+
+// NamedAtlas is one atlas produced by a model.AtlasGroup.
+type NamedAtlas struct {
+	Name  string
+	Atlas model.Atlas
+	Image *image.RGBA
+}
+
+// SpriteCount counts the sprites in every atlas of b.
+func (b *Build) SpriteCount() int {
+	n := len(b.Atlas.Sprites)
+	for _, a := range b.Named {
+		n += len(a.Atlas.Sprites)
+	}
+	return n
+}
+
+// CompileBuild compiles a single input. Its folder or file name is the unit
+// name, so the Unity asset guid does not depend on where the input is
+// checked out or how its path is spelled.
+func CompileBuild(inputPath string, cfg model.Config) (*Build, error) {
+	return compileUnit(inputPath, inputName(inputPath), cfg)
+}
+
+// inputName is the base name of inputPath, with "." and ".." resolved to
+// the folder they stand for.
+func inputName(inputPath string) string {
+	if abs, err := filepath.Abs(inputPath); err == nil {
+		inputPath = abs
+	}
+	return filepath.Base(inputPath)
+}
+
+// compileUnit compiles inputPath; unitName, behind cfg.UnityGUIDSeed when
+// set, seeds identifiers that must stay stable for the unit across
+// machines, such as the Unity asset guid.
+func compileUnit(inputPath, unitName string, cfg model.Config) (*Build, error) {
+	if err := ValidateConfig(cfg); err != nil {
+		return nil, err
+	}
+	guidSeed := unitName
+	if cfg.UnityGUIDSeed != "" {
+		guidSeed = cfg.UnityGUIDSeed + "/" + unitName
+	}
 
 	info, err := os.Stat(inputPath)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("stat input: %w", err)
+		return nil, atStage(StageLoad, fmt.Errorf("stat input: %w", err))
 	}
 
-	sprites, err := loadSprites(inputPath, info.IsDir(), cfg)
+	groups, err := loadGroupedSprites(inputPath, info.IsDir(), cfg)
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, err
+	}
+
+	b := &Build{}
+	if len(cfg.Atlases) == 0 || len(groups[0]) > 0 {
+		atlas, atlasImg, files, err := buildAtlas(groups[0], cfg, "atlas", guidSeed, false)
+		if err != nil {
+			return nil, err
+		}
+		b.Atlas, b.Image, b.Files = atlas, atlasImg, files
+	}
+	for i, g := range cfg.Atlases {
+		if len(groups[i+1]) == 0 {
+			continue
+		}
+		atlas, atlasImg, files, err := buildAtlas(groups[i+1], g.Apply(cfg), g.Name, guidSeed+"/"+g.Name, true)
+		if err != nil {
+			return nil, fmt.Errorf("atlas %s: %w", g.Name, err)
+		}
+		b.Files = append(b.Files, files...)
+		b.Named = append(b.Named, NamedAtlas{Name: g.Name, Atlas: atlas, Image: atlasImg})
+	}
+	if len(b.Files) == 0 {
+		return nil, atStage(StageLoad, fmt.Errorf("no sprites found in %s", inputPath))
+	}
+	if cfg.Embed != "" {
+		pngData, err := imageutil.EncodePNG(b.Image)
+		if err != nil {
+			return nil, atStage(StageExport, err)
+		}
+		src, err := exporter.ExportEmbed(b.Atlas, pngData, cfg.Embed)
+		if err != nil {
+			return nil, atStage(StageExport, err)
+		}
+		b.Files = append(b.Files, src)
+	}
+	for _, lang := range cfg.Codegen {
+		src, err := exporter.ExportCodegen(b.Atlas, lang, effectiveFPS(cfg))
+		if err != nil {
+			return nil, atStage(StageExport, err)
+		}
+		b.Files = append(b.Files, src)
+	}
+	seen := map[string]bool{"atlas.png": b.Image != nil}
+	for _, f := range b.Files {
+		if seen[f.Name] {
+			return nil, atStage(StageExport, fmt.Errorf("more than one output writes %s", f.Name))
+		}
+		seen[f.Name] = true
+	}
+	return b, nil
+}
+
+// buildAtlas processes, packs and exports the sprites of one atlas. name is
+// the output base name ("atlas" for the default atlas) and guidSeed seeds
+// its Unity meta. With withImage the atlas PNG is included in the files, as
+// named atlases need; each extra scale always adds its PNG.
+func buildAtlas(sprites []model.Sprite, cfg model.Config, name, guidSeed string, withImage bool) (model.Atlas, *image.RGBA, []exporter.File, error) {
+	sprites, err := processSprites(sprites, cfg)
+	if err != nil {
+		return model.Atlas{}, nil, nil, err
 	}
 
 	atlas, atlasImg, err := packer.Pack(sprites, cfg)
 	if err != nil {
-		return nil, nil, nil, err
+		return model.Atlas{}, nil, nil, atStage(StagePack, err)
 	}
 
-	presetJSON, err := exportPreset(cfg.Preset, atlas, cfg)
+	imageName := name + ".png"
+	variants, scaled, err := scaleVariants(atlas, atlasImg, cfg, name)
 	if err != nil {
-		return nil, nil, nil, err
+		return model.Atlas{}, nil, nil, err
+	}
+	files, err := exportPresets(exporter.Input{Atlas: atlas, ImageName: imageName, Scale: 1, Variants: variants}, cfg)
+	if err != nil {
+		return model.Atlas{}, nil, nil, atStage(StageExport, err)
+	}
+	if cfg.Tileset != "" {
+		tsx, err := exporter.ExportTiledTileset(atlas, exporter.TiledOptions{
+			Mode: cfg.Tileset, Name: name, ImageName: imageName, FPS: effectiveFPS(cfg), Spacing: cfg.Padding, Margin: cfg.Padding,
+		})
+		if err != nil {
+			return model.Atlas{}, nil, nil, atStage(StageExport, err)
+		}
+		files = append(files, tsx...)
+	}
+	if cfg.UnityMeta {
+		meta, err := exporter.ExportUnityMeta(atlas, guidSeed)
+		if err != nil {
+			return model.Atlas{}, nil, nil, atStage(StageExport, err)
+		}
+		files = append(files, exporter.File{Name: "atlas.png.meta", Data: meta})
+	}
+	for i := range files {
+		files[i].Name = atlasFileName(name, files[i].Name)
+	}
+	if withImage {
+		pngData, err := imageutil.EncodePNG(atlasImg)
+		if err != nil {
+			return model.Atlas{}, nil, nil, atStage(StageExport, err)
+		}
+		files = append(files, exporter.File{Name: imageName, Data: pngData})
 	}
-	return &atlas, atlasImg, presetJSON, nil
+
+	for i, v := range scaled {
+		out, err := exportPresets(exporter.Input{Atlas: v.atlas, ImageName: variants[i+1].ImageName, Scale: variants[i+1].Scale, Variants: variants}, cfg)
+		if err != nil {
+			return model.Atlas{}, nil, nil, atStage(StageExport, err)
+		}
+		base := strings.TrimSuffix(variants[i+1].ImageName, ".png")
+		for _, f := range out {
+			files = append(files, exporter.File{Name: atlasFileName(base, f.Name), Data: f.Data})
+		}
+		pngData, err := imageutil.EncodePNG(v.img)
+		if err != nil {
+			return model.Atlas{}, nil, nil, atStage(StageExport, err)
+		}
+		files = append(files, exporter.File{Name: variants[i+1].ImageName, Data: pngData})
+	}
+	return atlas, atlasImg, files, nil
 }
 
-func exportPreset(preset string, atlas model.Atlas, cfg model.Config) ([]byte, error) {
-	switch preset {
-	case "unity":
-		return exporter.ExportUnity(atlas, "atlas.png", version.Version, effectiveFPS(cfg))
-	case "godot", "custom":
-		return nil, fmt.Errorf("preset %s not implemented", preset)
-	default:
-		return nil, fmt.Errorf("unsupported preset: %s", preset)
+type scaledAtlas struct {
+	atlas model.Atlas
+	img   *image.RGBA
+}
+
+// scaleVariants resamples the atlas for every extra scale in cfg. The
+// variants table starts with the atlas itself and is nil without extras.
+func scaleVariants(atlas model.Atlas, atlasImg *image.RGBA, cfg model.Config, name string) ([]exporter.Variant, []scaledAtlas, error) {
+	extra := cfg.ExtraScales()
+	if len(extra) == 0 {
+		return nil, nil, nil
 	}
+	variants := []exporter.Variant{{Scale: 1, ImageName: name + ".png", Width: atlas.Width, Height: atlas.Height}}
+	scaled := make([]scaledAtlas, 0, len(extra))
+	for _, factor := range extra {
+		a, img, err := scale.Atlas(atlas, atlasImg, factor, cfg.ScaleFilter)
+		if err != nil {
+			return nil, nil, atStage(StageExport, fmt.Errorf("scale %g: %w", factor, err))
+		}
+		variants = append(variants, exporter.Variant{Scale: factor, ImageName: fmt.Sprintf("%s@%gx.png", name, factor), Width: a.Width, Height: a.Height})
+		scaled = append(scaled, scaledAtlas{atlas: a, img: img})
+	}
+	return variants, scaled, nil
 }
 
-func loadSprites(inputPath string, isDir bool, cfg model.Config) ([]model.Sprite, error) {
-	if isDir {
-		return loadFolderSprites(inputPath, cfg)
+// atlasFileName renames a file written for the default atlas to the one for
+// atlas name: atlas.json becomes ui.json and preview.html ui.preview.html.
+func atlasFileName(name, file string) string {
+	if name == "atlas" {
+		return file
 	}
-	if strings.EqualFold(filepath.Ext(inputPath), ".png") {
-		img, err := imageutil.LoadPNG(inputPath)
+	if rest, ok := strings.CutPrefix(file, "atlas."); ok {
+		return name + "." + rest
+	}
+	return name + "." + file
+}
+
+// ValidateConfig is model.Config.Validate plus the preset checks that need
+// the exporter registry.
+func ValidateConfig(cfg model.Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	return exporter.ValidatePresets(cfg.Presets())
+}
+
+// exportPresets runs every preset in cfg through the exporter registry and
+// concatenates their files in the order the presets were listed. in only
+// needs the atlas, image and variant fields; the rest come from cfg.
+func exportPresets(in exporter.Input, cfg model.Config) ([]exporter.File, error) {
+	in.Version, in.FPS, in.Config = version.Version, effectiveFPS(cfg), cfg
+	files := make([]exporter.File, 0)
+	owner := map[string]string{}
+	for _, name := range cfg.Presets() {
+		e, err := exporter.Resolve(name)
+		if err != nil {
+			return nil, err
+		}
+		out, err := e.Export(in)
 		if err != nil {
-			return nil, fmt.Errorf("load spritesheet: %w", err)
+			return nil, fmt.Errorf("preset %s: %w", name, err)
 		}
-		sprites, err := slicer.SliceSpritesheet(img, cfg)
+		for _, f := range out {
+			if prev, ok := owner[f.Name]; ok {
+				return nil, fmt.Errorf("presets %s and %s both write %s", prev, name, f.Name)
+			}
+			owner[f.Name] = name
+		}
+		files = append(files, out...)
+	}
+	return files, nil
+}
+
+// loadGroupedSprites loads the unit's frames, untrimmed, and routes them to
+// atlases: index 0 is the default atlas and index i+1 is cfg.Atlases[i].
+func loadGroupedSprites(inputPath string, isDir bool, cfg model.Config) ([][]model.Sprite, error) {
+	groups := make([][]model.Sprite, len(cfg.Atlases)+1)
+	var sprites []model.Sprite
+	switch {
+	case isDir:
+		frames, err := loadFolderFrames(inputPath)
 		if err != nil {
 			return nil, err
 		}
-		return processSprites(sprites, cfg)
+		sprites = frames
+		for i, g := range cfg.Atlases {
+			if g.Folder == "" {
+				continue
+			}
+			// Units without the folder simply have no sprites for the group.
+			frames, err := loadFolderFrames(filepath.Join(inputPath, filepath.FromSlash(g.Folder)))
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("atlas %s: %w", g.Name, err)
+			}
+			groups[i+1] = append(groups[i+1], frames...)
+		}
+	case strings.EqualFold(filepath.Ext(inputPath), ".png"):
+		img, err := imageutil.LoadPNG(inputPath)
+		if err != nil {
+			return nil, atStage(StageLoad, fmt.Errorf("load spritesheet: %w", err))
+		}
+		sprites, err = slicer.SliceSpritesheet(img, cfg)
+		if err != nil {
+			return nil, atStage(StageSlice, err)
+		}
+	default:
+		return nil, atStage(StageLoad, fmt.Errorf("unsupported input: expected .png file or directory"))
 	}
-	return nil, fmt.Errorf("unsupported input: expected .png file or directory")
+	for _, s := range sprites {
+		i := matchAtlas(s.Name, cfg.Atlases)
+		groups[i] = append(groups[i], s)
+	}
+	return groups, nil
+}
+
+// matchAtlas returns the group index of the first atlas with a Match glob
+// for name, or 0 for the default atlas.
+func matchAtlas(name string, atlases []model.AtlasGroup) int {
+	for i, g := range atlases {
+		for _, m := range g.Match {
+			if globMatch(m, name) {
+				return i + 1
+			}
+		}
+	}
+	return 0
 }
 
-func loadFolderSprites(dir string, cfg model.Config) ([]model.Sprite, error) {
+func loadFolderFrames(dir string) ([]model.Sprite, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
-		return nil, fmt.Errorf("read folder: %w", err)
+		return nil, atStage(StageLoad, fmt.Errorf("read folder: %w", err))
 	}
 	files := make([]string, 0)
 	for _, e := range entries {
@@ -95,30 +378,135 @@ func loadFolderSprites(dir string, cfg model.Config) ([]model.Sprite, error) {
 		path := filepath.Join(dir, f)
 		img, err := imageutil.LoadPNG(path)
 		if err != nil {
-			return nil, fmt.Errorf("load frame %s: %w", f, err)
+			return nil, atStage(StageLoad, fmt.Errorf("load frame %s: %w", f, err))
+		}
+		s := model.Sprite{Name: strings.TrimSuffix(f, filepath.Ext(f))}
+		if nineslice.IsNinePatch(f) {
+			img, s.Border, err = nineslice.StripGuides(img)
+			if err != nil {
+				return nil, atStage(StageLoad, fmt.Errorf("load frame %s: %w", f, err))
+			}
+			s.Name = nineslice.SpriteName(f)
+		} else if data, err := os.ReadFile(filepath.Join(dir, asepriteSidecar(f))); err == nil {
+			border, ok, err := nineslice.AsepriteBorder(data, s.Name, img.Bounds().Dx(), img.Bounds().Dy())
+			if err != nil {
+				return nil, atStage(StageLoad, fmt.Errorf("load frame %s: %w", f, err))
+			}
+			if ok {
+				s.Border = border
+			}
 		}
-		sprites = append(sprites, model.Sprite{Name: strings.TrimSuffix(f, filepath.Ext(f)), Image: img, Width: img.Bounds().Dx(), Height: img.Bounds().Dy()})
+		s.Image, s.Width, s.Height = img, img.Bounds().Dx(), img.Bounds().Dy()
+		sprites = append(sprites, s)
 	}
-	return processSprites(sprites, cfg)
+	return sprites, nil
+}
+
+// asepriteSidecar names the Aseprite JSON export whose slices give frame
+// the border of its nine-slice center, e.g. panel.json for panel.png.
+func asepriteSidecar(frame string) string {
+	return strings.TrimSuffix(frame, filepath.Ext(frame)) + ".json"
 }
 
 func processSprites(sprites []model.Sprite, cfg model.Config) ([]model.Sprite, error) {
 	processed := make([]model.Sprite, 0, len(sprites))
+	modes := make([]string, 0, len(sprites))
 	for _, s := range sprites {
 		if s.Name == "" {
 			s.Name = fmt.Sprintf("sprite_%d_%d", s.X, s.Y)
 		}
-		trimmed, err := trim.TrimSprite(s)
-		if err != nil {
-			return nil, err
+		spriteCfg := cfg
+		rule, ok := matchRule(s.Name, cfg.Rules)
+		if ok {
+			s.Rule = rule.Match
+			if rule.PivotMode != "" {
+				spriteCfg.PivotMode = rule.PivotMode
+			}
+			if !rule.Border.IsZero() {
+				s.Border = rule.Border
+			}
+		}
+		if !s.Border.IsZero() {
+			if s.Border.Left+s.Border.Right > s.Width || s.Border.Top+s.Border.Bottom > s.Height {
+				return nil, atStage(StageTrim, fmt.Errorf("sprite %s border exceeds its %dx%d size", s.Name, s.Width, s.Height))
+			}
+		}
+		if (ok && rule.NoTrim) || !s.Border.IsZero() {
+			s.SourceWidth, s.SourceHeight = s.Width, s.Height
+		} else {
+			trimmed, err := trim.TrimSprite(s)
+			if err != nil {
+				return nil, atStage(StageTrim, err)
+			}
+			s = trimmed
 		}
-		pivoted, err := pivot.ApplyPivot(trimmed, cfg)
+		if cfg.Collision {
+			polys, err := collider.Build(s.Image, cfg.CollisionTolerance, cfg.CollisionConvex)
+			if err != nil {
+				return nil, atStage(StageTrim, err)
+			}
+			s.Polygons = polys
+		}
+		if cfg.Mesh {
+			m, err := mesh.Build(s.Image, cfg.MeshMaxVertices)
+			if err != nil {
+				return nil, atStage(StageTrim, err)
+			}
+			s.Mesh = m
+		}
+		pivoted, err := pivot.ApplyPivot(s, spriteCfg)
 		if err != nil {
-			return nil, err
+			return nil, atStage(StagePivot, err)
 		}
 		processed = append(processed, pivoted)
+		modes = append(modes, spriteCfg.PivotMode)
+	}
+	return applyAnimationPivots(processed, modes)
+}
+
+// applyAnimationPivots re-anchors sprites using an anim-* pivot mode so every
+// frame of the same animation state and mode shares one canvas-space pivot.
+func applyAnimationPivots(sprites []model.Sprite, modes []string) ([]model.Sprite, error) {
+	groups := map[string][]int{}
+	keys := make([]string, 0)
+	for i, s := range sprites {
+		if !model.IsAnimationPivot(modes[i]) {
+			continue
+		}
+		p := anim.ParseFrameName(s.Name)
+		if !p.Grouped {
+			continue
+		}
+		key := modes[i] + "\x00" + p.State
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], i)
+	}
+	for _, key := range keys {
+		idxs := groups[key]
+		frames := make([]model.Sprite, 0, len(idxs))
+		for _, i := range idxs {
+			frames = append(frames, sprites[i])
+		}
+		anchored, err := pivot.ApplyAnimationPivot(frames, modes[idxs[0]])
+		if err != nil {
+			return nil, atStage(StagePivot, err)
+		}
+		for j, i := range idxs {
+			sprites[i] = anchored[j]
+		}
+	}
+	return sprites, nil
+}
+
+func matchRule(name string, rules []model.SpriteRule) (model.SpriteRule, bool) {
+	for _, r := range rules {
+		if globMatch(r.Match, name) {
+			return r, true
+		}
 	}
-	return processed, nil
+	return model.SpriteRule{}, false
 }
 
 func effectiveFPS(cfg model.Config) int {
@@ -1,14 +1,18 @@
 package compiler
 
 import (
+	"encoding/json"
 	"fmt"
 	"image"
 	"image/color"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"pixelc/internal/imageutil"
 	"pixelc/pkg/model"
+	"pixelc/pkg/schema"
 )
 
 func TestCompileReturnsValidationError(t *testing.T) {
@@ -18,6 +22,76 @@ func TestCompileReturnsValidationError(t *testing.T) {
 	}
 }
 
+func TestCompileMultiplePresets(t *testing.T) {
+	path := makeSpritesheet(t)
+	cfg := model.Config{Connectivity: 4, Padding: 1, PivotMode: "center", Preset: "unity, libgdx,starling"}
+	_, img, files, err := CompileFiles(path, cfg)
+	if err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+	if got := strings.Join(FileNames(img, files), ","); got != "atlas.png,atlas.json,atlas.atlas,atlas.xml" {
+		t.Fatalf("unexpected files %s", got)
+	}
+	for _, preset := range []string{"invalid", "unity,texturepacker-array"} {
+		cfg.Preset = preset
+		if _, _, _, err := CompileFiles(path, cfg); err == nil {
+			t.Fatalf("expected preset %q to be rejected", preset)
+		}
+	}
+	cfg.Preset, cfg.Codegen = "unity", []string{"go", "go"}
+	if _, _, _, err := CompileFiles(path, cfg); err == nil || !strings.Contains(err.Error(), "atlas_sprites.go") {
+		t.Fatalf("expected duplicate codegen output to be rejected, got %v", err)
+	}
+}
+
+func TestCompileUnityGUIDIsPathIndependent(t *testing.T) {
+	root := t.TempDir()
+	mkpng(t, filepath.Join(root, "a", "ui", "button.png"), color.RGBA{R: 255, A: 255})
+	mkpng(t, filepath.Join(root, "b", "ui", "button.png"), color.RGBA{R: 255, A: 255})
+	cfg := model.Config{Connectivity: 4, PivotMode: "center", Preset: "unity", UnityMeta: true}
+	guid := func(input string) string {
+		t.Helper()
+		b, err := CompileBuild(input, cfg)
+		if err != nil {
+			t.Fatalf("compile failed: %v", err)
+		}
+		for _, f := range b.Files {
+			if f.Name == "atlas.png.meta" {
+				return string(f.Data)
+			}
+		}
+		t.Fatalf("no unity meta written")
+		return ""
+	}
+	abs := guid(filepath.Join(root, "a", "ui"))
+	if abs != guid(filepath.Join(root, "b", "ui")) {
+		t.Fatalf("guid depends on the checkout location")
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	if err := os.Chdir(filepath.Join(root, "a")); err != nil {
+		t.Fatal(err)
+	}
+	if abs != guid("ui") || abs != guid("ui"+string(filepath.Separator)) {
+		t.Fatalf("relative and absolute invocations give different guids")
+	}
+	if err := os.Chdir("ui"); err != nil {
+		t.Fatal(err)
+	}
+	if abs != guid(".") {
+		t.Fatalf("compiling . does not use the folder name")
+	}
+
+	cfg.UnityGUIDSeed = "game-b"
+	if seeded := guid("."); seeded == abs || seeded != guid(filepath.Join(root, "b", "ui")) {
+		t.Fatalf("unity guid seed not applied consistently")
+	}
+}
+
 func TestCompiler_BasicSpritesheet(t *testing.T) {
 	path := makeSpritesheet(t)
 	cfg := model.Config{Connectivity: 4, Padding: 1, PivotMode: "bottom-center", Preset: "unity"}
@@ -75,6 +149,197 @@ func TestCompiler_FolderInput(t *testing.T) {
 	}
 }
 
+func TestCompiler_SpriteRules(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"bullet_001.png", "hero_idle_001.png"} {
+		img := image.NewRGBA(image.Rect(0, 0, 6, 6))
+		img.SetRGBA(1, 1, color.RGBA{R: 255, A: 255})
+		img.SetRGBA(2, 1, color.RGBA{R: 255, A: 255})
+		img.SetRGBA(1, 2, color.RGBA{R: 255, A: 255})
+		if err := imageutil.SavePNG(filepath.Join(dir, name), img); err != nil {
+			t.Fatalf("save frame: %v", err)
+		}
+	}
+	cfg := model.Config{Connectivity: 4, PivotMode: "center", Preset: "unity", Rules: []model.SpriteRule{
+		{Match: "hero_*", PivotMode: "bottom-left", NoTrim: true},
+		{Match: "*", PivotMode: "top-left"},
+	}}
+	b, err := CompileBuild(dir, cfg)
+	if err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+	atlas := b.Atlas
+	byName := map[string]model.Sprite{}
+	for _, ps := range atlas.Sprites {
+		byName[ps.Sprite.Name] = ps.Sprite
+	}
+	hero, bullet := byName["hero_idle_001"], byName["bullet_001"]
+	if hero.Rule != "hero_*" || hero.Width != 6 || hero.PivotX != 0 || hero.PivotY != 1 {
+		t.Fatalf("hero rule not applied: %+v", hero)
+	}
+	if bullet.Rule != "*" || bullet.Width != 2 || bullet.PivotX != 0 || bullet.PivotY != 0 {
+		t.Fatalf("fallback rule not applied: %+v", bullet)
+	}
+
+	rep, err := buildUnitReport("u", b, nil)
+	if err != nil {
+		t.Fatalf("report failed: %v", err)
+	}
+	var parsed struct {
+		SpriteRules map[string]string `json:"sprite_rules"`
+	}
+	if err := json.Unmarshal(rep, &parsed); err != nil {
+		t.Fatalf("unmarshal report: %v", err)
+	}
+	if parsed.SpriteRules["hero_idle_001"] != "hero_*" || parsed.SpriteRules["bullet_001"] != "*" {
+		t.Fatalf("unexpected report rules: %v", parsed.SpriteRules)
+	}
+}
+
+func TestCompiler_AnimationPivotIsStable(t *testing.T) {
+	dir := t.TempDir()
+	// frame 2 lifts a foot so its trimmed bottom edge moves up.
+	feet := map[string][][2]int{
+		"hero_walk_001.png": {{2, 2}, {2, 3}, {2, 4}, {3, 4}},
+		"hero_walk_002.png": {{2, 2}, {2, 3}, {3, 3}},
+		"coin.png":          {{1, 1}, {2, 1}},
+	}
+	for name, px := range feet {
+		img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+		for _, p := range px {
+			img.SetRGBA(p[0], p[1], color.RGBA{R: 255, A: 255})
+		}
+		if err := imageutil.SavePNG(filepath.Join(dir, name), img); err != nil {
+			t.Fatalf("save frame: %v", err)
+		}
+	}
+	cfg := model.Config{Connectivity: 4, PivotMode: "anim-bottom-center", Preset: "unity"}
+	atlas, _, _, err := Compile(dir, cfg)
+	if err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+	anchors := map[string][2]float64{}
+	for _, ps := range atlas.Sprites {
+		s := ps.Sprite
+		anchors[s.Name] = [2]float64{float64(s.OffsetX) + s.PivotX*float64(s.Width), float64(s.OffsetY) + s.PivotY*float64(s.Height)}
+	}
+	if anchors["hero_walk_001"] != anchors["hero_walk_002"] || anchors["hero_walk_001"] != [2]float64{3, 5} {
+		t.Fatalf("walk anchors drift: %v", anchors)
+	}
+	if anchors["coin"] != [2]float64{2, 2} {
+		t.Fatalf("ungrouped sprite anchor: %v", anchors["coin"])
+	}
+}
+
+func TestCompiler_NineSliceBorders(t *testing.T) {
+	dir := t.TempDir()
+	patch := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for i := 3; i <= 4; i++ {
+		patch.SetRGBA(i, 0, color.RGBA{A: 255})
+		patch.SetRGBA(0, i, color.RGBA{A: 255})
+	}
+	patch.SetRGBA(2, 2, color.RGBA{G: 255, A: 255})
+	if err := imageutil.SavePNG(filepath.Join(dir, "panel.9.png"), patch); err != nil {
+		t.Fatalf("save frame: %v", err)
+	}
+	button := image.NewRGBA(image.Rect(0, 0, 6, 6))
+	button.SetRGBA(2, 2, color.RGBA{B: 255, A: 255})
+	if err := imageutil.SavePNG(filepath.Join(dir, "button.png"), button); err != nil {
+		t.Fatalf("save frame: %v", err)
+	}
+
+	window := image.NewRGBA(image.Rect(0, 0, 10, 8))
+	window.SetRGBA(5, 4, color.RGBA{R: 255, A: 255})
+	if err := imageutil.SavePNG(filepath.Join(dir, "window.png"), window); err != nil {
+		t.Fatalf("save frame: %v", err)
+	}
+	slices := `{"meta":{"slices":[{"name":"window","keys":[{"frame":0,"bounds":{"x":0,"y":0,"w":10,"h":8},"center":{"x":3,"y":2,"w":4,"h":3}}]}]}}`
+	if err := os.WriteFile(filepath.Join(dir, "window.json"), []byte(slices), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := model.Config{Connectivity: 4, PivotMode: "center", Preset: "unity,godot", Rules: []model.SpriteRule{
+		{Match: "button", Border: model.Border{Left: 1, Top: 2, Right: 1, Bottom: 2}},
+	}}
+	atlas, _, files, err := CompileFiles(dir, cfg)
+	if err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+	presetJSON := files[0].Data
+	byName := map[string]model.Sprite{}
+	for _, ps := range atlas.Sprites {
+		byName[ps.Sprite.Name] = ps.Sprite
+	}
+	if p := byName["panel"]; p.Width != 6 || p.Height != 6 || p.Border != (model.Border{Left: 2, Top: 2, Right: 2, Bottom: 2}) {
+		t.Fatalf("unexpected panel sprite: %+v", p)
+	}
+	if b := byName["button"]; b.Width != 6 || b.Border.Top != 2 {
+		t.Fatalf("unexpected button sprite: %+v", b)
+	}
+	if w := byName["window"]; w.Width != 10 || w.Border != (model.Border{Left: 3, Top: 2, Right: 3, Bottom: 3}) {
+		t.Fatalf("aseprite slice not applied: %+v", w)
+	}
+	var out schema.UnityAtlasJSON
+	if err := json.Unmarshal(presetJSON, &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if b := out.Frames["button"].Border; b == nil || b.Left != 1 || b.Bottom != 2 {
+		t.Fatalf("border not exported: %s", presetJSON)
+	}
+	var godot schema.GodotAtlasJSON
+	if err := json.Unmarshal(files[1].Data, &godot); err != nil {
+		t.Fatalf("unmarshal godot: %v", err)
+	}
+	if sb := godot.Frames["window"].StyleBox; sb == nil || sb.TextureMarginLeft != 3 || sb.TextureMarginBottom != 3 || sb.RegionRect.W != 10 {
+		t.Fatalf("style box not exported: %s", files[1].Data)
+	}
+
+	cfg.Rules[0].Border.Left = 9
+	if _, _, _, err := Compile(dir, cfg); err == nil || ErrorStage(err) != StageTrim {
+		t.Fatalf("expected oversized border error at the trim stage got %v", err)
+	}
+}
+
+func TestCompiler_CollisionPolygons(t *testing.T) {
+	dir := makeFolderFrames(t, 2)
+	cfg := model.Config{Connectivity: 4, PivotMode: "center", Preset: "unity,godot", Collision: true, CollisionConvex: true}
+	atlas, _, files, err := CompileFiles(dir, cfg)
+	if err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+	presetJSON := files[0].Data
+	for _, ps := range atlas.Sprites {
+		if len(ps.Sprite.Polygons) != 2 {
+			t.Fatalf("expected L frame split into 2 convex parts, got %v", ps.Sprite.Polygons)
+		}
+	}
+	var out schema.UnityAtlasJSON
+	if err := json.Unmarshal(presetJSON, &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(out.Frames["frame_000"].Polygons) != 2 {
+		t.Fatalf("polygons not exported: %s", presetJSON)
+	}
+	var godot schema.GodotAtlasJSON
+	if err := json.Unmarshal(files[1].Data, &godot); err != nil {
+		t.Fatalf("unmarshal godot: %v", err)
+	}
+	if len(godot.Frames["frame_000"].Polygons) != 2 {
+		t.Fatalf("polygons not exported to godot: %s", files[1].Data)
+	}
+
+	cfg.Preset = "unity"
+
+	cfg.Collision = false
+	_, _, plain, err := Compile(dir, cfg)
+	if err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+	if strings.Contains(string(plain), "polygons") {
+		t.Fatalf("polygons exported without collision enabled")
+	}
+}
+
 func BenchmarkCompiler_Folder_200Frames(b *testing.B) {
 	dir := makeFolderFramesBench(b, 200)
 	cfg := model.Config{Connectivity: 4, Padding: 1, PivotMode: "center", Preset: "unity", PowerOfTwo: true}
@@ -157,3 +422,11 @@ func assertWithinBounds(t *testing.T, atlas *model.Atlas) {
 		}
 	}
 }
+
+func TestCompileErrorStages(t *testing.T) {
+	dir := t.TempDir()
+	cfg := model.Config{Connectivity: 4, Padding: 0, PivotMode: "center", Preset: "unity"}
+	if _, _, _, err := Compile(filepath.Join(dir, "missing.png"), cfg); ErrorStage(err) != StageLoad {
+		t.Fatalf("missing input: expected load stage got %q (%v)", ErrorStage(err), err)
+	}
+}
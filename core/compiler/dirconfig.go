@@ -0,0 +1,199 @@
+package compiler
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"pixelc/pkg/jsonconf"
+	"pixelc/pkg/model"
+)
+
+// DirConfigName is the per-directory config file read during batch discovery.
+const DirConfigName = "pixelc.json"
+
+// DirConfig is a pixelc.json inside a batch input tree. Set fields override
+// the parent directory's settings for that subtree; absent or null fields
+// inherit them. Ignore patterns are relative to the directory and add to the
+// parent's. The desc and minimum tags feed DirConfigSchema.
+type DirConfig struct {
+	Padding   *int     `json:"padding" desc:"padding in pixels between sprites" minimum:"0"`
+	PivotMode *string  `json:"pivotMode" desc:"pivot mode, e.g. center, bottom-center, custom:x,y"`
+	Preset    *string  `json:"preset" desc:"export preset(s), comma-separated"`
+	FPS       *int     `json:"fps" desc:"animation frames per second" minimum:"0"`
+	Ignore    []string `json:"ignore" desc:"glob patterns, relative to this directory, excluded from the batch"`
+}
+
+// DirConfigSchema returns the JSON Schema for pixelc.json.
+func DirConfigSchema() ([]byte, error) {
+	return jsonconf.Schema(DirConfig{}, DirConfigName)
+}
+
+// ConfigValue is one effective setting and the file or flag it came from.
+type ConfigValue struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// ConfigExplanation is the result of ExplainUnit.
+type ConfigExplanation struct {
+	Unit   string        `json:"unit"`
+	Chain  []string      `json:"chain"`
+	Values []ConfigValue `json:"values"`
+}
+
+// dirState is the merged config in effect for a directory during discovery.
+type dirState struct {
+	cfg    model.Config
+	chain  []string
+	ignore []scopedPattern
+}
+
+// scopedPattern is an ignore pattern relative to dir ("" for the root).
+type scopedPattern struct {
+	dir, pattern string
+}
+
+func (s dirState) ignores(rel string) bool {
+	for _, p := range s.ignore {
+		sub := rel
+		if p.dir != "" {
+			if !strings.HasPrefix(rel, p.dir+"/") {
+				continue
+			}
+			sub = strings.TrimPrefix(rel, p.dir+"/")
+		}
+		if shouldIgnore(sub, []string{p.pattern}) {
+			return true
+		}
+	}
+	return false
+}
+
+// enter returns the state for directory rel (slash-separated, "" for the
+// root) by applying its pixelc.json, if any, on top of s.
+func (s dirState) enter(root, rel string) (dirState, error) {
+	dc, err := loadDirConfig(filepath.Join(root, filepath.FromSlash(rel)))
+	if err != nil || dc == nil {
+		return s, err
+	}
+	source := path.Join(rel, DirConfigName)
+	next := dirState{cfg: s.cfg, chain: append(append([]string{}, s.chain...), source), ignore: s.ignore}
+	dc.apply(&next.cfg)
+	if len(dc.Ignore) > 0 {
+		next.ignore = append([]scopedPattern{}, s.ignore...)
+		for _, p := range dc.Ignore {
+			next.ignore = append(next.ignore, scopedPattern{dir: rel, pattern: p})
+		}
+	}
+	if err := ValidateConfig(next.cfg); err != nil {
+		return s, fmt.Errorf("%s: %w", source, err)
+	}
+	return next, nil
+}
+
+func loadDirConfig(dir string) (*DirConfig, error) {
+	p := filepath.Join(dir, DirConfigName)
+	data, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var dc DirConfig
+	if err := jsonconf.Decode(data, &dc); err != nil {
+		return nil, fmt.Errorf("%s:%w", p, err)
+	}
+	return &dc, nil
+}
+
+func (d DirConfig) apply(cfg *model.Config) {
+	if d.Padding != nil {
+		cfg.Padding = *d.Padding
+	}
+	if d.PivotMode != nil {
+		cfg.PivotMode = *d.PivotMode
+	}
+	if d.Preset != nil {
+		cfg.Preset = *d.Preset
+	}
+	if d.FPS != nil {
+		cfg.FPS = *d.FPS
+	}
+}
+
+// ExplainUnit reports the effective padding, pivotMode, preset, fps and
+// ignore settings for unit, a directory under inputPath, and where each came
+// from. baseSources names the origin of cfg's values by key; missing keys
+// are reported as "default".
+func ExplainUnit(inputPath, unit string, cfg model.Config, ignore []string, baseSources map[string]string) (*ConfigExplanation, error) {
+	rel := filepath.ToSlash(filepath.Clean(unit))
+	if rel == "." {
+		rel = ""
+	}
+	if rel == ".." || strings.HasPrefix(rel, "../") || filepath.IsAbs(unit) {
+		return nil, fmt.Errorf("unit %s is outside %s", unit, inputPath)
+	}
+	if info, err := os.Stat(filepath.Join(inputPath, filepath.FromSlash(rel))); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("unit %s is not a directory under %s", unit, inputPath)
+	}
+
+	base := func(key string) string {
+		if s, ok := baseSources[key]; ok {
+			return s
+		}
+		return "default"
+	}
+	sources := map[string]string{"padding": base("padding"), "pivotMode": base("pivotMode"), "preset": base("preset"), "fps": base("fps")}
+	ignoreValues := make([]ConfigValue, 0, len(ignore))
+	for _, p := range ignore {
+		ignoreValues = append(ignoreValues, ConfigValue{Key: "ignore", Value: p, Source: base("ignore")})
+	}
+
+	dirs := []string{""}
+	if rel != "" {
+		parts := strings.Split(rel, "/")
+		for i := range parts {
+			dirs = append(dirs, strings.Join(parts[:i+1], "/"))
+		}
+	}
+	out := &ConfigExplanation{Unit: unit, Chain: []string{}}
+	for _, dir := range dirs {
+		dc, err := loadDirConfig(filepath.Join(inputPath, filepath.FromSlash(dir)))
+		if err != nil {
+			return nil, err
+		}
+		if dc == nil {
+			continue
+		}
+		source := path.Join(dir, DirConfigName)
+		out.Chain = append(out.Chain, source)
+		dc.apply(&cfg)
+		for key, set := range map[string]bool{"padding": dc.Padding != nil, "pivotMode": dc.PivotMode != nil, "preset": dc.Preset != nil, "fps": dc.FPS != nil} {
+			if set {
+				sources[key] = source
+			}
+		}
+		for _, p := range dc.Ignore {
+			v := p
+			if dir != "" {
+				v = dir + "/" + p
+			}
+			ignoreValues = append(ignoreValues, ConfigValue{Key: "ignore", Value: v, Source: source})
+		}
+	}
+	out.Values = []ConfigValue{
+		{Key: "padding", Value: strconv.Itoa(cfg.Padding), Source: sources["padding"]},
+		{Key: "pivotMode", Value: cfg.PivotMode, Source: sources["pivotMode"]},
+		{Key: "preset", Value: cfg.Preset, Source: sources["preset"]},
+		{Key: "fps", Value: strconv.Itoa(cfg.FPS), Source: sources["fps"]},
+	}
+	out.Values = append(out.Values, ignoreValues...)
+	return out, nil
+}
@@ -0,0 +1,138 @@
+package compiler
+
+import (
+	"encoding/json"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pixelc/pkg/model"
+)
+
+func writeDirConfig(t *testing.T, dir, data string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, DirConfigName), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func dirConfigTree(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	mkpng(t, filepath.Join(root, "chars", "hero", "hero_idle_001.png"), color.RGBA{R: 255, A: 255})
+	mkpng(t, filepath.Join(root, "chars", "old", "legacy_001.png"), color.RGBA{R: 255, A: 255})
+	mkpng(t, filepath.Join(root, "ui", "button_001.png"), color.RGBA{B: 255, A: 255})
+	writeDirConfig(t, root, `{"padding":3}`)
+	writeDirConfig(t, filepath.Join(root, "chars"), `{"pivotMode":"bottom-center","ignore":["old"]}`)
+	writeDirConfig(t, filepath.Join(root, "chars", "hero"), `{"fps":24}`)
+	return root
+}
+
+func TestCompileBatchDirConfigs(t *testing.T) {
+	root := dirConfigTree(t)
+	cfg := model.Config{Connectivity: 4, Padding: 1, PivotMode: "center", Preset: "unity", FPS: 12}
+	res, err := CompileBatch(root, cfg, BatchOptions{OutDir: filepath.Join(t.TempDir(), "out"), WriteReport: true})
+	if err != nil {
+		t.Fatalf("batch compile failed: %v", err)
+	}
+	if len(res.Units) != 2 || res.Units[0].UnitName != "chars/hero" || res.Units[1].UnitName != "ui" {
+		t.Fatalf("unexpected units %+v", res.Units)
+	}
+	hero, ui := res.Units[0], res.Units[1]
+	if p := hero.Atlas.Sprites[0].Sprite; p.PivotY != 1 {
+		t.Fatalf("chars/pixelc.json pivot not applied: %+v", p)
+	}
+	if p := ui.Atlas.Sprites[0].Sprite; p.PivotY == 1 {
+		t.Fatalf("chars/pixelc.json leaked into ui: %+v", p)
+	}
+	if hero.Atlas.Sprites[0].AtlasX != 3 || ui.Atlas.Sprites[0].AtlasX != 3 {
+		t.Fatalf("root padding not applied")
+	}
+	if !strings.Contains(string(hero.JSON), `"fps":24`) || !strings.Contains(string(ui.JSON), `"fps":12`) {
+		t.Fatalf("fps overrides wrong: %s / %s", hero.JSON, ui.JSON)
+	}
+	var rep struct {
+		ConfigChain []string `json:"config_chain"`
+	}
+	if err := json.Unmarshal(hero.Report, &rep); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(rep.ConfigChain, ",") != "pixelc.json,chars/pixelc.json,chars/hero/pixelc.json" {
+		t.Fatalf("unexpected config chain %v", rep.ConfigChain)
+	}
+}
+
+func TestCompileBatchInvalidDirConfig(t *testing.T) {
+	root := dirConfigTree(t)
+	writeDirConfig(t, filepath.Join(root, "ui"), `{"pivotMode":"sideways"}`)
+	cfg := model.Config{Connectivity: 4, PivotMode: "center", Preset: "unity"}
+	_, err := CompileBatch(root, cfg, BatchOptions{OutDir: filepath.Join(t.TempDir(), "out")})
+	if err == nil || !strings.Contains(err.Error(), "ui/pixelc.json") {
+		t.Fatalf("expected ui/pixelc.json error got %v", err)
+	}
+}
+
+func TestExplainUnit(t *testing.T) {
+	root := dirConfigTree(t)
+	cfg := model.Config{Connectivity: 4, Padding: 1, PivotMode: "center", Preset: "unity", FPS: 12}
+	ex, err := ExplainUnit(root, "chars/hero", cfg, []string{"**/temp/**"}, map[string]string{"preset": "--preset", "ignore": "--ignore"})
+	if err != nil {
+		t.Fatalf("explain failed: %v", err)
+	}
+	got := []string{}
+	for _, v := range ex.Values {
+		got = append(got, v.Key+"="+v.Value+" ("+v.Source+")")
+	}
+	want := []string{
+		"padding=3 (pixelc.json)",
+		"pivotMode=bottom-center (chars/pixelc.json)",
+		"preset=unity (--preset)",
+		"fps=24 (chars/hero/pixelc.json)",
+		"ignore=**/temp/** (--ignore)",
+		"ignore=chars/old (chars/pixelc.json)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected explanation:\n%s", strings.Join(got, "\n"))
+	}
+	if _, err := ExplainUnit(root, "../elsewhere", cfg, nil, nil); err == nil {
+		t.Fatal("expected an error for a unit outside the input")
+	}
+}
+
+func TestDirConfigStrict(t *testing.T) {
+	root := dirConfigTree(t)
+	writeDirConfig(t, filepath.Join(root, "ui"), "{\n  \"pading\": 4\n}")
+	cfg := model.Config{Connectivity: 4, PivotMode: "center", Preset: "unity"}
+	_, err := CompileBatch(root, cfg, BatchOptions{OutDir: filepath.Join(t.TempDir(), "out")})
+	if err == nil || !strings.HasSuffix(err.Error(), filepath.Join("ui", DirConfigName)+`:2:3: unknown key "pading"`) {
+		t.Fatalf("expected a positioned unknown key error got %v", err)
+	}
+
+	writeDirConfig(t, filepath.Join(root, "ui"), `{"padding": null, "fps": 30}`)
+	res, err := CompileBatch(root, cfg, BatchOptions{OutDir: filepath.Join(t.TempDir(), "out")})
+	if err != nil {
+		t.Fatalf("batch compile failed: %v", err)
+	}
+	if ui := res.Units[1]; ui.Atlas.Sprites[0].AtlasX != 3 || !strings.Contains(string(ui.JSON), `"fps":30`) {
+		t.Fatalf("null padding should inherit the root value: %+v", ui.Atlas.Sprites[0])
+	}
+}
+
+func TestDirConfigSchemaMatchesDocs(t *testing.T) {
+	got, err := DirConfigSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile(filepath.Join("..", "..", "docs", "pixelc.schema.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got)+"\n" != string(want) {
+		t.Fatalf("docs/pixelc.schema.json is stale; regenerate it with pixelc config schema")
+	}
+}
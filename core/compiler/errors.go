@@ -3,3 +3,41 @@ package compiler
 import "errors"
 
 var ErrNotImplemented = errors.New("not implemented")
+
+// Pipeline stages reported by StageError.
+const (
+	StageLoad   = "load"
+	StageSlice  = "slice"
+	StageTrim   = "trim"
+	StagePivot  = "pivot"
+	StagePack   = "pack"
+	StageExport = "export"
+)
+
+// StageError records which pipeline stage a compile failed in. Its message
+// is the wrapped error's, so callers that only print errors see no change.
+type StageError struct {
+	Stage string
+	Err   error
+}
+
+func (e *StageError) Error() string { return e.Err.Error() }
+
+func (e *StageError) Unwrap() error { return e.Err }
+
+// ErrorStage returns the stage err was tagged with, or "" if none.
+func ErrorStage(err error) string {
+	var se *StageError
+	if errors.As(err, &se) {
+		return se.Stage
+	}
+	return ""
+}
+
+// atStage tags err with stage unless an inner call already tagged it.
+func atStage(stage string, err error) error {
+	if err == nil || ErrorStage(err) != "" {
+		return err
+	}
+	return &StageError{Stage: stage, Err: err}
+}
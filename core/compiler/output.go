@@ -5,29 +5,64 @@ import (
 	"image"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"pixelc/core/exporter"
 	"pixelc/internal/imageutil"
-	"pixelc/pkg/model"
 )
 
 func WriteOutputs(outDir string, atlasImg *image.RGBA, presetJSON []byte) error {
-	if atlasImg == nil {
+	return WriteFiles(outDir, atlasImg, []exporter.File{{Name: "atlas.json", Data: presetJSON}})
+}
+
+// WriteFiles writes atlasImg as atlas.png and then files. atlasImg may be
+// nil only when files carry the atlas images themselves, as for a Build
+// whose sprites all went to named atlases.
+func WriteFiles(outDir string, atlasImg *image.RGBA, files []exporter.File) error {
+	if atlasImg == nil && !hasPNG(files) {
 		return fmt.Errorf("nil atlas image")
 	}
 	if err := os.MkdirAll(outDir, 0o755); err != nil {
 		return fmt.Errorf("create output directory: %w", err)
 	}
-	if err := imageutil.SavePNG(filepath.Join(outDir, "atlas.png"), atlasImg); err != nil {
-		return fmt.Errorf("write atlas.png: %w", err)
+	if atlasImg != nil {
+		if err := imageutil.SavePNG(filepath.Join(outDir, "atlas.png"), atlasImg); err != nil {
+			return fmt.Errorf("write atlas.png: %w", err)
+		}
 	}
-	if err := os.WriteFile(filepath.Join(outDir, "atlas.json"), presetJSON, 0o644); err != nil {
-		return fmt.Errorf("write atlas.json: %w", err)
+	for _, f := range files {
+		if err := os.WriteFile(filepath.Join(outDir, f.Name), f.Data, 0o644); err != nil {
+			return fmt.Errorf("write %s: %w", f.Name, err)
+		}
 	}
 	return nil
 }
 
-func WriteSingleReport(outDir, unitName string, atlas model.Atlas, atlasImg *image.RGBA, presetJSON []byte) ([]byte, error) {
-	rep, err := buildUnitReport(unitName, atlas, atlasImg, presetJSON)
+func hasPNG(files []exporter.File) bool {
+	for _, f := range files {
+		if strings.EqualFold(filepath.Ext(f.Name), ".png") {
+			return true
+		}
+	}
+	return false
+}
+
+// FileNames lists the files WriteFiles produces for atlasImg and files,
+// atlas first.
+func FileNames(atlasImg *image.RGBA, files []exporter.File) []string {
+	names := make([]string, 0, len(files)+1)
+	if atlasImg != nil {
+		names = append(names, "atlas.png")
+	}
+	for _, f := range files {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// WriteSingleReport writes report.json for a Build compiled outside a batch.
+func WriteSingleReport(outDir, unitName string, b *Build) ([]byte, error) {
+	rep, err := buildUnitReport(unitName, b, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,61 @@
+package compiler
+
+import (
+	"encoding/json"
+	"image/color"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pixelc/pkg/model"
+	"pixelc/pkg/schema"
+)
+
+func TestCompileScaleVariants(t *testing.T) {
+	root := t.TempDir()
+	mkpng(t, filepath.Join(root, "hero_idle_001.png"), color.RGBA{R: 255, A: 255})
+	mkpng(t, filepath.Join(root, "ui_button.png"), color.RGBA{G: 255, A: 255})
+	cfg := model.Config{Connectivity: 4, Padding: 1, PivotMode: "bottom-center", Preset: "unity,css", FPS: 12, Scales: []float64{1, 2, 0.5},
+		Atlases: []model.AtlasGroup{{Name: "ui", Match: []string{"ui_*"}}}}
+	b, err := CompileBuild(root, cfg)
+	if err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+	want := "atlas.png,atlas.json,atlas.css,preview.html," +
+		"atlas@2x.json,atlas@2x.css,atlas@2x.preview.html,atlas@2x.png,atlas@0.5x.json,atlas@0.5x.css,atlas@0.5x.preview.html,atlas@0.5x.png," +
+		"ui.json,ui.css,ui.preview.html,ui.png,ui@2x.json,ui@2x.css,ui@2x.preview.html,ui@2x.png,ui@0.5x.json,ui@0.5x.css,ui@0.5x.preview.html,ui@0.5x.png"
+	if got := strings.Join(FileNames(b.Image, b.Files), ","); got != want {
+		t.Fatalf("unexpected files %s", got)
+	}
+	byName := map[string][]byte{}
+	for _, f := range b.Files {
+		byName[f.Name] = f.Data
+	}
+
+	var base, hd schema.UnityAtlasJSON
+	if err := json.Unmarshal(byName["atlas.json"], &base); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(byName["atlas@2x.json"], &hd); err != nil {
+		t.Fatal(err)
+	}
+	if hd.Meta.Image != "atlas@2x.png" || hd.Meta.Scale != 2 || hd.Meta.Size.W != 2*base.Meta.Size.W || len(hd.Meta.Variants) != 3 {
+		t.Fatalf("unexpected 2x meta %+v", hd.Meta)
+	}
+	if v := base.Meta.Variants; len(v) != 3 || v[0] != (schema.Variant{Scale: 1, Image: "atlas.png", W: base.Meta.Size.W, H: base.Meta.Size.H}) || v[1].Image != "atlas@2x.png" || v[2].Scale != 0.5 {
+		t.Fatalf("unexpected variants table %+v", v)
+	}
+	f1, f2 := base.Frames["hero_idle_001"], hd.Frames["hero_idle_001"]
+	if f2.Frame.X != 2*f1.Frame.X || f2.Frame.W != 2*f1.Frame.W || f2.Frame.H != 2*f1.Frame.H || f2.Pivot != f1.Pivot {
+		t.Fatalf("2x frame not scaled consistently: %+v vs %+v", f2, f1)
+	}
+	if css := string(byName["atlas.css"]); !strings.Contains(css, `url("atlas@2x.png")`) || strings.Contains(css, "atlas@0.5x.png") {
+		t.Fatalf("css variants not wired:\n%s", css)
+	}
+	if strings.Contains(string(byName["atlas@2x.css"]), "@media") {
+		t.Fatalf("variant css repeats the media queries")
+	}
+	if !strings.Contains(string(byName["ui@2x.json"]), `"image":"ui@2x.png"`) {
+		t.Fatalf("named atlas variant does not reference its image")
+	}
+}
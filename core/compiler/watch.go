@@ -0,0 +1,116 @@
+package compiler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"pixelc/pkg/model"
+)
+
+type WatchOptions struct {
+	Interval time.Duration // how often the input tree is polled
+	Debounce time.Duration // quiet period after the last change before rebuilding
+}
+
+// WatchEvent describes one rebuild. Err is set when the batch could not run
+// at all; per-unit failures are in Result.Failures.
+type WatchEvent struct {
+	Duration time.Duration
+	Result   *BatchResult
+	Err      error
+}
+
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// Watch builds inputPath as a batch, then polls it and rebuilds after every
+// change until ctx is done. Units whose inputs did not change are served from
+// the build cache, so only affected units are recompiled. Failures are
+// reported to onBuild and never stop the watch.
+func Watch(ctx context.Context, inputPath string, cfg model.Config, opts BatchOptions, wopts WatchOptions, onBuild func(WatchEvent)) error {
+	if err := ValidateConfig(cfg); err != nil {
+		return err
+	}
+	if wopts.Interval <= 0 {
+		wopts.Interval = 500 * time.Millisecond
+	}
+	opts.KeepGoing = true
+	opts.DryRun = false
+	skip := append([]string{}, opts.IgnorePatterns...)
+	if rel, err := filepath.Rel(inputPath, opts.OutDir); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		skip = append(skip, rel)
+	}
+
+	build := func() {
+		start := time.Now()
+		res, err := CompileBatch(inputPath, cfg, opts)
+		onBuild(WatchEvent{Duration: time.Since(start), Result: res, Err: err})
+		opts.Force = false
+	}
+	last := snapshotTree(inputPath, skip)
+	build()
+
+	ticker := time.NewTicker(wopts.Interval)
+	defer ticker.Stop()
+	var changedAt time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			cur := snapshotTree(inputPath, skip)
+			if !sameSnapshot(last, cur) {
+				last, changedAt = cur, now
+				continue
+			}
+			if !changedAt.IsZero() && now.Sub(changedAt) >= wopts.Debounce {
+				changedAt = time.Time{}
+				build()
+			}
+		}
+	}
+}
+
+// snapshotTree stats every file under root. Unreadable entries are left out;
+// the rebuild that follows reports the actual error.
+func snapshotTree(root string, ignore []string) map[string]fileStamp {
+	snap := map[string]fileStamp{}
+	allIgnore := append([]string{".git", "node_modules", "build", "dist"}, ignore...)
+	_ = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		if rel != "." && shouldIgnore(rel, allIgnore) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			snap[rel] = fileStamp{size: info.Size(), modTime: info.ModTime()}
+		}
+		return nil
+	})
+	return snap
+}
+
+func sameSnapshot(a, b map[string]fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || !w.modTime.Equal(v.modTime) || w.size != v.size {
+			return false
+		}
+	}
+	return true
+}
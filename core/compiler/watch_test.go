@@ -0,0 +1,63 @@
+package compiler
+
+import (
+	"context"
+	"image/color"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"pixelc/pkg/model"
+)
+
+func TestWatchRebuildsChangedUnits(t *testing.T) {
+	root := t.TempDir()
+	mkpng(t, filepath.Join(root, "a", "hero_idle_001.png"), color.RGBA{R: 255, A: 255})
+	mkpng(t, filepath.Join(root, "b", "enemy_run_001.png"), color.RGBA{B: 255, A: 255})
+	cfg := model.Config{Connectivity: 4, Padding: 1, PivotMode: "center", Preset: "unity", FPS: 12}
+	out := filepath.Join(t.TempDir(), "out")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan WatchEvent, 8)
+	done := make(chan error, 1)
+	go func() {
+		done <- Watch(ctx, root, cfg, BatchOptions{OutDir: out}, WatchOptions{Interval: 10 * time.Millisecond, Debounce: 30 * time.Millisecond}, func(ev WatchEvent) {
+			events <- ev
+		})
+	}()
+	next := func() WatchEvent {
+		t.Helper()
+		select {
+		case ev := <-events:
+			if ev.Err != nil {
+				t.Fatalf("rebuild failed: %v", ev.Err)
+			}
+			return ev
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for a rebuild")
+		}
+		return WatchEvent{}
+	}
+	built := func(ev WatchEvent) []string {
+		names := []string{}
+		for _, u := range ev.Result.Units {
+			if !u.Cached {
+				names = append(names, u.UnitName)
+			}
+		}
+		return names
+	}
+
+	if got := built(next()); len(got) != 2 {
+		t.Fatalf("initial build: expected both units got %v", got)
+	}
+	mkpng(t, filepath.Join(root, "b", "enemy_run_002.png"), color.RGBA{G: 255, A: 255})
+	if got := built(next()); len(got) != 1 || got[0] != "b" {
+		t.Fatalf("expected only b rebuilt got %v", got)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("watch returned %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package exporter
+
+import (
+	"fmt"
+
+	"pixelc/core/anim"
+	"pixelc/pkg/atlasbin"
+	"pixelc/pkg/model"
+)
+
+// ExportBinary writes the atlasbin format: the same frames, pivots, borders
+// and animations as ExportUnity, sorted by name, plus trim source data.
+func ExportBinary(atlas model.Atlas, atlasImageName string, fps int) ([]byte, error) {
+	if err := atlas.Validate(); err != nil {
+		return nil, err
+	}
+	if atlasImageName == "" {
+		return nil, fmt.Errorf("atlas image name is required")
+	}
+	out := atlasbin.Atlas{Image: atlasImageName, Width: atlas.Width, Height: atlas.Height}
+	index := map[string]int{}
+	names := make([]string, 0, len(atlas.Sprites))
+	for _, ps := range sortedSprites(atlas) {
+		s := ps.Sprite
+		srcW, srcH := sourceSize(s)
+		index[s.Name] = len(out.Frames)
+		names = append(names, s.Name)
+		out.Frames = append(out.Frames, atlasbin.Frame{
+			Name: s.Name, X: ps.AtlasX, Y: ps.AtlasY, W: s.Width, H: s.Height,
+			SourceWidth: srcW, SourceHeight: srcH, OffsetX: s.OffsetX, OffsetY: s.OffsetY,
+			PivotX: float32(s.PivotX), PivotY: float32(s.PivotY),
+			Border: atlasbin.Border{Left: s.Border.Left, Top: s.Border.Top, Right: s.Border.Right, Bottom: s.Border.Bottom},
+		})
+	}
+	anims, _, err := anim.BuildAnimations(names, fps)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range anims {
+		ba := atlasbin.Animation{State: a.State, FPS: a.FPS}
+		for _, f := range a.Frames {
+			ba.Frames = append(ba.Frames, index[f])
+		}
+		out.Animations = append(out.Animations, ba)
+	}
+	data, err := atlasbin.Encode(out)
+	if err != nil {
+		return nil, fmt.Errorf("encode atlas.bin: %w", err)
+	}
+	return data, nil
+}
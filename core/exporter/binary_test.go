@@ -0,0 +1,78 @@
+package exporter
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"pixelc/pkg/atlasbin"
+	"pixelc/pkg/model"
+	"pixelc/pkg/schema"
+)
+
+func TestExportBinaryMatchesUnityJSON(t *testing.T) {
+	atlas := goldenAtlas()
+	atlas.Sprites = append(atlas.Sprites, model.PlacedSprite{
+		Sprite: model.Sprite{Name: "panel", Width: 4, Height: 4, PivotX: 0.25, PivotY: 0.75, Border: model.Border{Left: 1, Top: 1, Right: 2, Bottom: 1}},
+		AtlasX: 12, AtlasY: 1,
+	})
+	jsonData, err := ExportUnity(atlas, "atlas.png", "1.0.0", 10)
+	if err != nil {
+		t.Fatalf("unity export failed: %v", err)
+	}
+	var want schema.UnityAtlasJSON
+	if err := json.Unmarshal(jsonData, &want); err != nil {
+		t.Fatalf("unmarshal unity json: %v", err)
+	}
+	binData, err := ExportBinary(atlas, "atlas.png", 10)
+	if err != nil {
+		t.Fatalf("binary export failed: %v", err)
+	}
+	got, err := atlasbin.Decode(binData)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+
+	if got.Image != want.Meta.Image || got.Width != want.Meta.Size.W || got.Height != want.Meta.Size.H {
+		t.Fatalf("meta mismatch: %+v vs %+v", got, want.Meta)
+	}
+	if len(got.Frames) != len(want.Frames) {
+		t.Fatalf("frame count got %d want %d", len(got.Frames), len(want.Frames))
+	}
+	for i, f := range got.Frames {
+		if i > 0 && got.Frames[i-1].Name >= f.Name {
+			t.Fatalf("frames not sorted by name")
+		}
+		w, ok := want.Frames[f.Name]
+		if !ok {
+			t.Fatalf("unexpected frame %s", f.Name)
+		}
+		if f.X != w.Frame.X || f.Y != w.Frame.Y || f.W != w.Frame.W || f.H != w.Frame.H {
+			t.Fatalf("%s rect mismatch", f.Name)
+		}
+		if math.Abs(float64(f.PivotX)-w.Pivot.X) > 1e-6 || math.Abs(float64(f.PivotY)-w.Pivot.Y) > 1e-6 {
+			t.Fatalf("%s pivot mismatch", f.Name)
+		}
+		var wb atlasbin.Border
+		if w.Border != nil {
+			wb = atlasbin.Border{Left: w.Border.Left, Top: w.Border.Top, Right: w.Border.Right, Bottom: w.Border.Bottom}
+		}
+		if f.Border != wb {
+			t.Fatalf("%s border mismatch", f.Name)
+		}
+	}
+	if len(got.Animations) != len(want.Animations) {
+		t.Fatalf("animation count got %d want %d", len(got.Animations), len(want.Animations))
+	}
+	for _, a := range got.Animations {
+		w := want.Animations[a.State]
+		if a.FPS != w.FPS || len(a.Frames) != len(w.Frames) {
+			t.Fatalf("animation %s mismatch", a.State)
+		}
+		for i, fi := range a.Frames {
+			if got.Frames[fi].Name != w.Frames[i] {
+				t.Fatalf("animation %s frame %d got %s want %s", a.State, i, got.Frames[fi].Name, w.Frames[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+
+	"pixelc/pkg/model"
+)
+
+const plistHeader = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+`
+
+// ExportCocos2d writes a Cocos2d-x sprite frame plist (format 3). Cocos uses
+// a y-up frame: spriteOffset is the trimmed rect's centre relative to the
+// untrimmed centre and anchor is normalized against the untrimmed size.
+func ExportCocos2d(atlas model.Atlas, atlasImageName string) ([]byte, error) {
+	if err := atlas.Validate(); err != nil {
+		return nil, err
+	}
+	if atlasImageName == "" {
+		return nil, fmt.Errorf("atlas image name is required")
+	}
+	var buf bytes.Buffer
+	buf.WriteString(plistHeader)
+	buf.WriteString("<dict>\n")
+	plistKey(&buf, 1, "frames")
+	buf.WriteString("\t<dict>\n")
+	for _, ps := range sortedSprites(atlas) {
+		s := ps.Sprite
+		srcW, srcH := sourceSize(s)
+		offX := float64(s.OffsetX) + float64(s.Width)/2 - float64(srcW)/2
+		offY := float64(srcH)/2 - float64(s.OffsetY) - float64(s.Height)/2
+		anchorX := (float64(s.OffsetX) + s.PivotX*float64(s.Width)) / float64(srcW)
+		anchorY := 1 - (float64(s.OffsetY)+s.PivotY*float64(s.Height))/float64(srcH)
+
+		plistKey(&buf, 2, s.Name)
+		buf.WriteString("\t\t<dict>\n")
+		plistKey(&buf, 3, "aliases")
+		buf.WriteString("\t\t\t<array/>\n")
+		plistKey(&buf, 3, "anchor")
+		plistString(&buf, 3, fmt.Sprintf("{%s,%s}", fmtFloat(anchorX), fmtFloat(anchorY)))
+		plistKey(&buf, 3, "spriteOffset")
+		plistString(&buf, 3, fmt.Sprintf("{%s,%s}", fmtFloat(offX), fmtFloat(offY)))
+		plistKey(&buf, 3, "spriteSize")
+		plistString(&buf, 3, fmt.Sprintf("{%d,%d}", s.Width, s.Height))
+		plistKey(&buf, 3, "spriteSourceSize")
+		plistString(&buf, 3, fmt.Sprintf("{%d,%d}", srcW, srcH))
+		plistKey(&buf, 3, "textureRect")
+		plistString(&buf, 3, fmt.Sprintf("{{%d,%d},{%d,%d}}", ps.AtlasX, ps.AtlasY, s.Width, s.Height))
+		plistKey(&buf, 3, "textureRotated")
+		buf.WriteString("\t\t\t<false/>\n")
+		buf.WriteString("\t\t</dict>\n")
+	}
+	buf.WriteString("\t</dict>\n")
+	plistKey(&buf, 1, "metadata")
+	buf.WriteString("\t<dict>\n")
+	plistKey(&buf, 2, "format")
+	buf.WriteString("\t\t<integer>3</integer>\n")
+	plistKey(&buf, 2, "pixelFormat")
+	plistString(&buf, 2, "RGBA8888")
+	plistKey(&buf, 2, "premultiplyAlpha")
+	buf.WriteString("\t\t<false/>\n")
+	plistKey(&buf, 2, "realTextureFileName")
+	plistString(&buf, 2, atlasImageName)
+	plistKey(&buf, 2, "size")
+	plistString(&buf, 2, fmt.Sprintf("{%d,%d}", atlas.Width, atlas.Height))
+	plistKey(&buf, 2, "textureFileName")
+	plistString(&buf, 2, atlasImageName)
+	buf.WriteString("\t</dict>\n")
+	buf.WriteString("</dict>\n</plist>\n")
+	return buf.Bytes(), nil
+}
+
+func plistKey(buf *bytes.Buffer, depth int, key string) {
+	plistElem(buf, depth, "key", key)
+}
+
+func plistString(buf *bytes.Buffer, depth int, value string) {
+	plistElem(buf, depth, "string", value)
+}
+
+func plistElem(buf *bytes.Buffer, depth int, tag, value string) {
+	for i := 0; i < depth; i++ {
+		buf.WriteByte('\t')
+	}
+	fmt.Fprintf(buf, "<%s>", tag)
+	_ = xml.EscapeText(buf, []byte(value))
+	fmt.Fprintf(buf, "</%s>\n", tag)
+}
+
+func fmtFloat(f float64) string {
+	if f == 0 {
+		f = 0 // normalize -0
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,363 @@
+package exporter
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"pixelc/core/anim"
+	"pixelc/pkg/model"
+)
+
+type codegenSprite struct {
+	Name  string
+	Ident []string // identifier words
+	X     int
+	Y     int
+	W     int
+	H     int
+}
+
+type codegenAnim struct {
+	State  string
+	Ident  []string
+	FPS    int
+	Frames []int // indexes into the sprite list
+}
+
+// ExportCodegen writes a source file with constants for every sprite name,
+// its atlas rect and each animation state. Ordering follows the sorted sprite
+// names used by ExportUnity so regenerated files diff cleanly.
+func ExportCodegen(atlas model.Atlas, lang string, fps int) (File, error) {
+	if err := atlas.Validate(); err != nil {
+		return File{}, err
+	}
+	scope, ok := codegenScopes[lang]
+	if !ok {
+		return File{}, fmt.Errorf("unsupported codegen language: %s", lang)
+	}
+	ordered := sortedSprites(atlas)
+	sprites := make([]codegenSprite, 0, len(ordered))
+	index := map[string]int{}
+	names := make([]string, 0, len(ordered))
+	for _, ps := range ordered {
+		index[ps.Sprite.Name] = len(sprites)
+		names = append(names, ps.Sprite.Name)
+		sprites = append(sprites, codegenSprite{
+			Name: ps.Sprite.Name, Ident: identWords(ps.Sprite.Name),
+			X: ps.AtlasX, Y: ps.AtlasY, W: ps.Sprite.Width, H: ps.Sprite.Height,
+		})
+	}
+	built, _, err := anim.BuildAnimations(names, fps)
+	if err != nil {
+		return File{}, err
+	}
+	anims := make([]codegenAnim, 0, len(built))
+	for _, a := range built {
+		ca := codegenAnim{State: a.State, Ident: identWords(a.State), FPS: a.FPS}
+		for _, f := range a.Frames {
+			ca.Frames = append(ca.Frames, index[f])
+		}
+		anims = append(anims, ca)
+	}
+	used := map[string]bool{}
+	for _, name := range scope.reserved {
+		used[name] = true
+	}
+	dedupeIdents(used, len(sprites), func(i int) *[]string { return &sprites[i].Ident }, scope.sprite)
+	dedupeIdents(used, len(anims), func(i int) *[]string { return &anims[i].Ident }, scope.anim)
+
+	switch lang {
+	case "csharp":
+		return File{Name: "AtlasSprites.cs", Data: codegenCSharp(sprites, anims)}, nil
+	case "gdscript":
+		return File{Name: "atlas_sprites.gd", Data: codegenGDScript(sprites, anims)}, nil
+	case "typescript":
+		return File{Name: "atlas_sprites.ts", Data: codegenTypeScript(sprites, anims)}, nil
+	case "go":
+		src, err := format.Source(codegenGo(sprites, anims))
+		if err != nil {
+			return File{}, fmt.Errorf("format go source: %w", err)
+		}
+		return File{Name: "atlas_sprites.go", Data: src}, nil
+	}
+	return File{}, fmt.Errorf("unsupported codegen language: %s", lang)
+}
+
+// codegenScope lists the identifiers a sprite and an animation claim in one
+// language's output, qualified by the class or object that holds them, so
+// collisions are found on the names as they are written.
+type codegenScope struct {
+	sprite   func(words []string) []string
+	anim     func(words []string) []string
+	reserved []string
+}
+
+var codegenScopes = map[string]codegenScope{
+	"csharp": {
+		sprite: func(w []string) []string { return []string{"Names." + pascal(w), "Rects." + pascal(w)} },
+		anim: func(w []string) []string {
+			p := "Animations." + pascal(w)
+			return []string{p, p + "Fps", p + "Frames"}
+		},
+		// A member cannot share the name of the class that holds it.
+		reserved: []string{"Names.Names", "Rects.Rects", "Animations.Animations"},
+	},
+	"gdscript": {
+		sprite:   func(w []string) []string { return []string{upperSnake(w)} },
+		anim:     func(w []string) []string { return []string{"ANIM_" + upperSnake(w)} },
+		reserved: []string{"RECTS", "ANIMATIONS"},
+	},
+	"typescript": {
+		sprite: func(w []string) []string { return []string{"Sprites." + pascal(w)} },
+		anim:   func(w []string) []string { return []string{"Animations." + pascal(w)} },
+	},
+	"go": {
+		sprite: func(w []string) []string { return []string{"Sprite" + pascal(w)} },
+		anim:   func(w []string) []string { return []string{"Anim" + pascal(w)} },
+	},
+}
+
+const codegenHeader = "Code generated by pixelc. DO NOT EDIT."
+
+func codegenCSharp(sprites []codegenSprite, anims []codegenAnim) []byte {
+	var buf bytes.Buffer
+	w := func(format string, args ...any) { fmt.Fprintf(&buf, format+"\n", args...) }
+	w("// %s", codegenHeader)
+	w("public static class AtlasSprites")
+	w("{")
+	w("    public readonly struct Rect")
+	w("    {")
+	w("        public readonly int X, Y, W, H;")
+	w("        public Rect(int x, int y, int w, int h) { X = x; Y = y; W = w; H = h; }")
+	w("    }")
+	w("")
+	w("    public static class Names")
+	w("    {")
+	for _, s := range sprites {
+		w("        public const string %s = %s;", pascal(s.Ident), strconv.Quote(s.Name))
+	}
+	w("    }")
+	w("")
+	w("    public static class Rects")
+	w("    {")
+	for _, s := range sprites {
+		w("        public static readonly Rect %s = new Rect(%d, %d, %d, %d);", pascal(s.Ident), s.X, s.Y, s.W, s.H)
+	}
+	w("    }")
+	w("")
+	w("    public static class Animations")
+	w("    {")
+	for _, a := range anims {
+		frames := make([]string, 0, len(a.Frames))
+		for _, f := range a.Frames {
+			frames = append(frames, "Names."+pascal(sprites[f].Ident))
+		}
+		w("        public const string %s = %s;", pascal(a.Ident), strconv.Quote(a.State))
+		w("        public const int %sFps = %d;", pascal(a.Ident), a.FPS)
+		w("        public static readonly string[] %sFrames = { %s };", pascal(a.Ident), strings.Join(frames, ", "))
+	}
+	w("    }")
+	w("}")
+	return buf.Bytes()
+}
+
+func codegenGDScript(sprites []codegenSprite, anims []codegenAnim) []byte {
+	var buf bytes.Buffer
+	w := func(format string, args ...any) { fmt.Fprintf(&buf, format+"\n", args...) }
+	w("# %s", codegenHeader)
+	w("class_name AtlasSprites")
+	w("")
+	for _, s := range sprites {
+		w("const %s := %s", upperSnake(s.Ident), strconv.Quote(s.Name))
+	}
+	w("")
+	w("const RECTS := {")
+	for _, s := range sprites {
+		w("\t%s: Rect2i(%d, %d, %d, %d),", upperSnake(s.Ident), s.X, s.Y, s.W, s.H)
+	}
+	w("}")
+	w("")
+	for _, a := range anims {
+		w("const ANIM_%s := %s", upperSnake(a.Ident), strconv.Quote(a.State))
+	}
+	w("")
+	w("const ANIMATIONS := {")
+	for _, a := range anims {
+		frames := make([]string, 0, len(a.Frames))
+		for _, f := range a.Frames {
+			frames = append(frames, upperSnake(sprites[f].Ident))
+		}
+		w("\tANIM_%s: {\"fps\": %d, \"frames\": [%s]},", upperSnake(a.Ident), a.FPS, strings.Join(frames, ", "))
+	}
+	w("}")
+	return buf.Bytes()
+}
+
+func codegenTypeScript(sprites []codegenSprite, anims []codegenAnim) []byte {
+	var buf bytes.Buffer
+	w := func(format string, args ...any) { fmt.Fprintf(&buf, format+"\n", args...) }
+	w("// %s", codegenHeader)
+	w("")
+	w("export interface SpriteRect {")
+	w("  x: number;")
+	w("  y: number;")
+	w("  w: number;")
+	w("  h: number;")
+	w("}")
+	w("")
+	w("export const Sprites = {")
+	for _, s := range sprites {
+		w("  %s: %s,", pascal(s.Ident), strconv.Quote(s.Name))
+	}
+	w("} as const;")
+	w("")
+	w("export type SpriteName = (typeof Sprites)[keyof typeof Sprites];")
+	w("")
+	w("export const SpriteRects: Record<SpriteName, SpriteRect> = {")
+	for _, s := range sprites {
+		w("  [Sprites.%s]: { x: %d, y: %d, w: %d, h: %d },", pascal(s.Ident), s.X, s.Y, s.W, s.H)
+	}
+	w("};")
+	w("")
+	w("export const Animations = {")
+	for _, a := range anims {
+		w("  %s: %s,", pascal(a.Ident), strconv.Quote(a.State))
+	}
+	w("} as const;")
+	w("")
+	w("export type AnimationName = (typeof Animations)[keyof typeof Animations];")
+	w("")
+	w("export const AnimationFrames: Record<AnimationName, { fps: number; frames: readonly SpriteName[] }> = {")
+	for _, a := range anims {
+		frames := make([]string, 0, len(a.Frames))
+		for _, f := range a.Frames {
+			frames = append(frames, "Sprites."+pascal(sprites[f].Ident))
+		}
+		w("  [Animations.%s]: { fps: %d, frames: [%s] },", pascal(a.Ident), a.FPS, strings.Join(frames, ", "))
+	}
+	w("};")
+	return buf.Bytes()
+}
+
+func codegenGo(sprites []codegenSprite, anims []codegenAnim) []byte {
+	var buf bytes.Buffer
+	w := func(format string, args ...any) { fmt.Fprintf(&buf, format+"\n", args...) }
+	w("// %s", codegenHeader)
+	w("")
+	w("package atlas")
+	w("")
+	w("type Rect struct{ X, Y, W, H int }")
+	w("")
+	w("type Animation struct {")
+	w("FPS int")
+	w("Frames []string")
+	w("}")
+	w("")
+	w("const (")
+	for _, s := range sprites {
+		w("Sprite%s = %s", pascal(s.Ident), strconv.Quote(s.Name))
+	}
+	w(")")
+	w("")
+	w("var Rects = map[string]Rect{")
+	for _, s := range sprites {
+		w("Sprite%s: {%d, %d, %d, %d},", pascal(s.Ident), s.X, s.Y, s.W, s.H)
+	}
+	w("}")
+	if len(anims) == 0 {
+		return buf.Bytes()
+	}
+	w("")
+	w("const (")
+	for _, a := range anims {
+		w("Anim%s = %s", pascal(a.Ident), strconv.Quote(a.State))
+	}
+	w(")")
+	w("")
+	w("var Animations = map[string]Animation{")
+	for _, a := range anims {
+		frames := make([]string, 0, len(a.Frames))
+		for _, f := range a.Frames {
+			frames = append(frames, "Sprite"+pascal(sprites[f].Ident))
+		}
+		w("Anim%s: {FPS: %d, Frames: []string{%s}},", pascal(a.Ident), a.FPS, strings.Join(frames, ", "))
+	}
+	w("}")
+	return buf.Bytes()
+}
+
+// identWords splits a sprite or state name into lower-case ASCII words,
+// so "Hero-Walk_001" becomes hero, walk, 001.
+func identWords(name string) []string {
+	words := make([]string, 0)
+	var cur strings.Builder
+	flush := func() {
+		if cur.Len() > 0 {
+			words = append(words, cur.String())
+			cur.Reset()
+		}
+	}
+	for _, r := range name {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			cur.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		flush()
+	}
+	flush()
+	if len(words) == 0 {
+		words = append(words, "sprite")
+	}
+	return words
+}
+
+// dedupeIdents appends a counter to identifiers until none of the names
+// render gives them is in used, then marks those names used. Comparing
+// rendered names catches "hero_001" and "hero001" meeting as Hero001, or a
+// sprite "anim_walk" and a state "walk" meeting as ANIM_WALK. Input order is
+// sorted, so it is stable.
+func dedupeIdents(used map[string]bool, n int, ident func(int) *[]string, render func([]string) []string) {
+	for i := 0; i < n; i++ {
+		id := ident(i)
+		base := *id
+		for k := 2; anyUsed(used, render(*id)); k++ {
+			*id = append(append([]string(nil), base...), strconv.Itoa(k))
+		}
+		for _, name := range render(*id) {
+			used[name] = true
+		}
+	}
+}
+
+func anyUsed(used map[string]bool, names []string) bool {
+	for _, name := range names {
+		if used[name] {
+			return true
+		}
+	}
+	return false
+}
+
+func pascal(words []string) string {
+	var b strings.Builder
+	for _, w := range words {
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	out := b.String()
+	if out[0] >= '0' && out[0] <= '9' {
+		return "_" + out
+	}
+	return out
+}
+
+func upperSnake(words []string) string {
+	out := strings.ToUpper(strings.Join(words, "_"))
+	if out[0] >= '0' && out[0] <= '9' {
+		return "_" + out
+	}
+	return out
+}
@@ -0,0 +1,111 @@
+package exporter
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"pixelc/pkg/model"
+)
+
+func codegenAtlas() model.Atlas {
+	atlas := goldenAtlas()
+	atlas.Sprites = append(atlas.Sprites, model.PlacedSprite{Sprite: model.Sprite{Name: "Coin", Width: 2, Height: 2}, AtlasX: 13, AtlasY: 1})
+	return atlas
+}
+
+func TestExportCodegenGolden(t *testing.T) {
+	for _, lang := range []string{"csharp", "gdscript", "typescript", "go"} {
+		f, err := ExportCodegen(codegenAtlas(), lang, 10)
+		if err != nil {
+			t.Fatalf("%s: export failed: %v", lang, err)
+		}
+		name := f.Name
+		if filepath.Ext(name) == ".go" {
+			// Kept out of the module so the golden is not built as a package.
+			name += ".golden"
+		}
+		assertGoldenText(t, filepath.Join("codegen", name), f.Data)
+	}
+}
+
+func TestExportCodegenDeterministic(t *testing.T) {
+	a := codegenAtlas()
+	b := codegenAtlas()
+	b.Sprites[0], b.Sprites[2] = b.Sprites[2], b.Sprites[0]
+	fa, err := ExportCodegen(a, "typescript", 12)
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	fb, err := ExportCodegen(b, "typescript", 12)
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if string(fa.Data) != string(fb.Data) {
+		t.Fatal("codegen output depends on packing order")
+	}
+}
+
+func TestExportCodegenIdentifiers(t *testing.T) {
+	atlas := model.Atlas{Width: 8, Height: 8, Sprites: []model.PlacedSprite{
+		{Sprite: model.Sprite{Name: "2x-star", Width: 1, Height: 1}},
+		{Sprite: model.Sprite{Name: "ui_panel", Width: 1, Height: 1}, AtlasX: 2},
+		{Sprite: model.Sprite{Name: "ui-panel", Width: 1, Height: 1}, AtlasX: 4},
+	}}
+	f, err := ExportCodegen(atlas, "csharp", 12)
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	for _, want := range []string{"_2xStar = \"2x-star\"", "UiPanel = \"ui-panel\"", "UiPanel2 = \"ui_panel\""} {
+		if !strings.Contains(string(f.Data), want) {
+			t.Fatalf("missing %q in:\n%s", want, f.Data)
+		}
+	}
+	if _, err := ExportCodegen(atlas, "rust", 12); err == nil {
+		t.Fatal("expected unsupported language error")
+	}
+}
+
+func TestExportCodegenRenderedIdentifiersAreUnique(t *testing.T) {
+	atlas := model.Atlas{Width: 16, Height: 16}
+	// Each pair renders to the same identifier in at least one language.
+	for i, name := range []string{"hero_001", "hero001", "x_0", "x+fps_0", "anim_walk", "walk_0", "names", "rects"} {
+		atlas.Sprites = append(atlas.Sprites, model.PlacedSprite{Sprite: model.Sprite{Name: name, Width: 1, Height: 1}, AtlasX: i * 2})
+	}
+	decls := map[string]*regexp.Regexp{
+		"csharp":     regexp.MustCompile(`(?m)^\s+public (?:const|static readonly) \S+ (\w+) =|^    public static class (\w+)`),
+		"gdscript":   regexp.MustCompile(`(?m)^const (\w+) :=`),
+		"typescript": regexp.MustCompile(`(?m)^  (\w+): |^export const (\w+)`),
+		"go":         regexp.MustCompile(`(?m)^\t(\w+)\s+= "`),
+	}
+	wants := map[string][]string{
+		"csharp":     {"Hero001 = \"hero001\"", "Hero0012 = \"hero_001\"", "XFps2 = \"x+fps\"", "XFps = 12", "Names2 = \"names\""},
+		"gdscript":   {"ANIM_WALK := \"anim_walk\"", "ANIM_WALK_2 := \"walk\"", "RECTS_2 := \"rects\""},
+		"typescript": {"Hero001: \"hero001\"", "Hero0012: \"hero_001\""},
+		"go":         {"SpriteHero001  = \"hero001\"", "SpriteHero0012 = \"hero_001\""},
+	}
+	for lang, decl := range decls {
+		f, err := ExportCodegen(atlas, lang, 12)
+		if err != nil {
+			t.Fatalf("%s: export failed: %v", lang, err)
+		}
+		// Names are only compared inside the class or object that holds them.
+		seen := map[string]bool{}
+		for _, m := range decl.FindAllStringSubmatch(string(f.Data), -1) {
+			if len(m) > 2 && m[2] != "" {
+				seen = map[string]bool{}
+				continue
+			}
+			if seen[m[1]] {
+				t.Fatalf("%s: %s declared twice in:\n%s", lang, m[1], f.Data)
+			}
+			seen[m[1]] = true
+		}
+		for _, want := range wants[lang] {
+			if !strings.Contains(string(f.Data), want) {
+				t.Fatalf("%s: missing %q in:\n%s", lang, want, f.Data)
+			}
+		}
+	}
+}
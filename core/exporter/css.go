@@ -0,0 +1,158 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+
+	"pixelc/core/anim"
+	"pixelc/pkg/model"
+)
+
+// CSSOptions configures ExportCSS. Variants add a resolution media query per
+// extra scale that swaps in the higher-resolution image.
+type CSSOptions struct {
+	ImageName string
+	Variants  []CSSVariant
+}
+
+type CSSVariant struct {
+	Scale     float64
+	ImageName string
+}
+
+// ExportCSS writes one class per sprite positioned over the atlas image.
+// Class names are "sprite-" plus the sprite name lower-cased with runs of
+// non-alphanumerics collapsed to "-".
+func ExportCSS(atlas model.Atlas, opts CSSOptions) ([]byte, error) {
+	if err := atlas.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.ImageName == "" {
+		return nil, fmt.Errorf("atlas image name is required")
+	}
+	ordered := sortedSprites(atlas)
+	classes := cssClasses(ordered)
+	var buf bytes.Buffer
+	w := func(format string, args ...any) { fmt.Fprintf(&buf, format+"\n", args...) }
+	w(".sprite {")
+	w("  display: inline-block;")
+	w("  background-image: url(%q);", opts.ImageName)
+	w("  background-repeat: no-repeat;")
+	w("  image-rendering: pixelated;")
+	w("}")
+	for i, ps := range ordered {
+		w("")
+		w(".%s {", classes[i])
+		w("  width: %dpx;", ps.Sprite.Width)
+		w("  height: %dpx;", ps.Sprite.Height)
+		w("  background-position: %s %s;", cssPx(-ps.AtlasX), cssPx(-ps.AtlasY))
+		w("}")
+	}
+	for _, v := range opts.Variants {
+		if v.Scale <= 1 {
+			continue
+		}
+		w("")
+		w("@media (min-resolution: %gdppx), (-webkit-min-device-pixel-ratio: %g) {", v.Scale, v.Scale)
+		w("  .sprite {")
+		w("    background-image: url(%q);", v.ImageName)
+		w("    background-size: %dpx %dpx;", atlas.Width, atlas.Height)
+		w("  }")
+		w("}")
+	}
+	return buf.Bytes(), nil
+}
+
+// ExportPreviewHTML writes a standalone page that shows every sprite from
+// cssName and plays each inferred animation at its FPS.
+func ExportPreviewHTML(atlas model.Atlas, cssName string, fps int) ([]byte, error) {
+	if err := atlas.Validate(); err != nil {
+		return nil, err
+	}
+	ordered := sortedSprites(atlas)
+	classes := cssClasses(ordered)
+	byName := map[string]string{}
+	names := make([]string, 0, len(ordered))
+	for i, ps := range ordered {
+		byName[ps.Sprite.Name] = classes[i]
+		names = append(names, ps.Sprite.Name)
+	}
+	anims, _, err := anim.BuildAnimations(names, fps)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w := func(format string, args ...any) { fmt.Fprintf(&buf, format+"\n", args...) }
+	w("<!DOCTYPE html>")
+	w("<html>")
+	w("<head>")
+	w("<meta charset=\"utf-8\">")
+	w("<title>pixelc atlas preview</title>")
+	w("<link rel=\"stylesheet\" href=\"%s\">", html.EscapeString(cssName))
+	w("<style>")
+	w("body { font-family: sans-serif; background: #2b2b2b; color: #ddd; }")
+	w("figure { display: inline-block; margin: 8px; text-align: center; }")
+	w("figure .sprite { zoom: 4; outline: 1px dashed #555; }")
+	w("figcaption { font-size: 12px; }")
+	w("</style>")
+	w("</head>")
+	w("<body>")
+	if len(anims) > 0 {
+		w("<h2>Animations</h2>")
+		for _, a := range anims {
+			frames := make([]string, 0, len(a.Frames))
+			for _, f := range a.Frames {
+				frames = append(frames, byName[f])
+			}
+			list, err := json.Marshal(frames)
+			if err != nil {
+				return nil, fmt.Errorf("marshal preview frames: %w", err)
+			}
+			w("<figure><div class=\"sprite %s\" data-fps=\"%d\" data-frames=\"%s\"></div><figcaption>%s (%d fps)</figcaption></figure>",
+				frames[0], a.FPS, html.EscapeString(string(list)), html.EscapeString(a.State), a.FPS)
+		}
+	}
+	w("<h2>Sprites</h2>")
+	for i, ps := range ordered {
+		w("<figure><div class=\"sprite %s\"></div><figcaption>%s</figcaption></figure>", classes[i], html.EscapeString(ps.Sprite.Name))
+	}
+	w("<script>")
+	w("document.querySelectorAll(\"[data-frames]\").forEach(function (el) {")
+	w("  var frames = JSON.parse(el.dataset.frames), i = 0;")
+	w("  setInterval(function () {")
+	w("    el.classList.remove(frames[i]);")
+	w("    i = (i + 1) %% frames.length;")
+	w("    el.classList.add(frames[i]);")
+	w("  }, 1000 / Number(el.dataset.fps));")
+	w("});")
+	w("</script>")
+	w("</body>")
+	w("</html>")
+	return buf.Bytes(), nil
+}
+
+// cssClasses returns a unique class name per sprite, in the given order.
+func cssClasses(ordered []model.PlacedSprite) []string {
+	idents := make([][]string, len(ordered))
+	for i, ps := range ordered {
+		idents[i] = identWords(ps.Sprite.Name)
+	}
+	class := func(w []string) []string { return []string{"sprite-" + strings.Join(w, "-")} }
+	dedupeIdents(map[string]bool{}, len(idents), func(i int) *[]string { return &idents[i] }, class)
+	out := make([]string, len(idents))
+	for i, id := range idents {
+		out[i] = class(id)[0]
+	}
+	return out
+}
+
+func cssPx(v int) string {
+	if v == 0 {
+		return "0"
+	}
+	return fmt.Sprintf("%dpx", v)
+}
@@ -0,0 +1,42 @@
+package exporter
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pixelc/pkg/model"
+)
+
+func TestExportCSSGolden(t *testing.T) {
+	css, err := ExportCSS(goldenAtlas(), CSSOptions{ImageName: "atlas.png"})
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	assertGoldenText(t, filepath.Join("css", "atlas.css"), css)
+
+	page, err := ExportPreviewHTML(goldenAtlas(), "atlas.css", 8)
+	if err != nil {
+		t.Fatalf("preview failed: %v", err)
+	}
+	assertGoldenText(t, filepath.Join("css", "preview.html"), page)
+}
+
+func TestExportCSSVariants(t *testing.T) {
+	css, err := ExportCSS(goldenAtlas(), CSSOptions{ImageName: "atlas.png", Variants: []CSSVariant{{Scale: 1, ImageName: "atlas.png"}, {Scale: 2, ImageName: "atlas@2x.png"}}})
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if strings.Count(string(css), "@media") != 1 || !strings.Contains(string(css), `url("atlas@2x.png")`) || !strings.Contains(string(css), "background-size: 16px 8px;") {
+		t.Fatalf("missing 2x media query:\n%s", css)
+	}
+}
+
+func TestExportCSSValidation(t *testing.T) {
+	if _, err := ExportCSS(model.Atlas{Width: -1}, CSSOptions{ImageName: "atlas.png"}); err == nil {
+		t.Fatal("expected validation error")
+	}
+	if _, err := ExportCSS(goldenAtlas(), CSSOptions{}); err == nil {
+		t.Fatal("expected image name error")
+	}
+}
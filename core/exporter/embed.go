@@ -0,0 +1,158 @@
+package exporter
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+
+	"pixelc/pkg/model"
+)
+
+// ExportEmbed writes a source file that links the atlas into a program:
+// "go" embeds atlas.png with go:embed, "go-bytes" inlines the PNG as a byte
+// slice and "c" writes a header with a byte array. Each also carries a frame
+// table ordered by sprite name. pngData must be the bytes of atlas.png.
+func ExportEmbed(atlas model.Atlas, pngData []byte, mode string) (File, error) {
+	if err := atlas.Validate(); err != nil {
+		return File{}, err
+	}
+	if len(pngData) == 0 {
+		return File{}, fmt.Errorf("atlas png data is required")
+	}
+	ordered := sortedSprites(atlas)
+	switch mode {
+	case "go", "go-bytes":
+		src, err := format.Source(embedGo(atlas, ordered, pngData, mode == "go-bytes"))
+		if err != nil {
+			return File{}, fmt.Errorf("format go source: %w", err)
+		}
+		return File{Name: "atlas_embed.go", Data: src}, nil
+	case "c":
+		return File{Name: "atlas_embed.h", Data: embedC(atlas, ordered, pngData)}, nil
+	default:
+		return File{}, fmt.Errorf("unsupported embed mode: %s", mode)
+	}
+}
+
+func embedGo(atlas model.Atlas, ordered []model.PlacedSprite, pngData []byte, inline bool) []byte {
+	var buf bytes.Buffer
+	w := func(format string, args ...any) { fmt.Fprintf(&buf, format+"\n", args...) }
+	w("// %s", codegenHeader)
+	w("")
+	w("package atlas")
+	w("")
+	if inline {
+		w("// PNG holds the atlas image.")
+		w("var PNG = []byte{")
+		writeByteRows(&buf, pngData, "")
+		w("}")
+	} else {
+		w("import _ \"embed\"")
+		w("")
+		w("// PNG holds the atlas image.")
+		w("//")
+		w("//go:embed atlas.png")
+		w("var PNG []byte")
+	}
+	w("")
+	w("const (")
+	w("Width = %d", atlas.Width)
+	w("Height = %d", atlas.Height)
+	w(")")
+	w("")
+	w("// Frame is a sprite's rect in PNG with its pivot normalized to the rect, y down.")
+	w("type Frame struct {")
+	w("Name string")
+	w("X, Y, W, H int")
+	w("PivotX, PivotY float64")
+	w("}")
+	w("")
+	w("var Frames = []Frame{")
+	for _, ps := range ordered {
+		s := ps.Sprite
+		w("{%s, %d, %d, %d, %d, %s, %s},", strconv.Quote(s.Name), ps.AtlasX, ps.AtlasY, s.Width, s.Height, fmtFloat(s.PivotX), fmtFloat(s.PivotY))
+	}
+	w("}")
+	return buf.Bytes()
+}
+
+func embedC(atlas model.Atlas, ordered []model.PlacedSprite, pngData []byte) []byte {
+	var buf bytes.Buffer
+	w := func(format string, args ...any) { fmt.Fprintf(&buf, format+"\n", args...) }
+	w("/* %s */", codegenHeader)
+	w("#ifndef PIXELC_ATLAS_EMBED_H")
+	w("#define PIXELC_ATLAS_EMBED_H")
+	w("")
+	w("#define PIXELC_ATLAS_WIDTH %d", atlas.Width)
+	w("#define PIXELC_ATLAS_HEIGHT %d", atlas.Height)
+	w("#define PIXELC_ATLAS_FRAME_COUNT %d", len(ordered))
+	w("")
+	w("/* Sprite rect in the atlas PNG; pivot is normalized to the rect, y down. */")
+	w("typedef struct pixelc_frame {")
+	w("    const char *name;")
+	w("    int x, y, w, h;")
+	w("    float pivot_x, pivot_y;")
+	w("} pixelc_frame;")
+	w("")
+	w("static const unsigned int pixelc_atlas_png_len = %d;", len(pngData))
+	w("static const unsigned char pixelc_atlas_png[] = {")
+	writeByteRows(&buf, pngData, "    ")
+	w("};")
+	w("")
+	if len(ordered) == 0 {
+		// C has no zero-length arrays; FRAME_COUNT stays 0.
+		w("static const pixelc_frame pixelc_atlas_frames[1] = {{0}};")
+	} else {
+		w("static const pixelc_frame pixelc_atlas_frames[PIXELC_ATLAS_FRAME_COUNT] = {")
+		for _, ps := range ordered {
+			s := ps.Sprite
+			w("    {%s, %d, %d, %d, %d, %s, %s},", cString(s.Name), ps.AtlasX, ps.AtlasY, s.Width, s.Height, cFloat(s.PivotX), cFloat(s.PivotY))
+		}
+		w("};")
+	}
+	w("")
+	w("#endif")
+	return buf.Bytes()
+}
+
+func writeByteRows(buf *bytes.Buffer, data []byte, indent string) {
+	for i := 0; i < len(data); i += 16 {
+		row := data[i:min(i+16, len(data))]
+		parts := make([]string, len(row))
+		for j, b := range row {
+			parts[j] = fmt.Sprintf("0x%02x", b)
+		}
+		fmt.Fprintf(buf, "%s%s,\n", indent, strings.Join(parts, ", "))
+	}
+}
+
+// cString quotes s as a C string literal, escaping anything outside
+// printable ASCII as octal.
+func cString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c > 0x7e:
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func cFloat(f float64) string {
+	s := fmtFloat(f)
+	if !strings.ContainsAny(s, ".eE") {
+		s += ".0"
+	}
+	return s + "f"
+}
@@ -0,0 +1,69 @@
+package exporter
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pixelc/pkg/model"
+)
+
+var fakePNG = []byte("\x89PNG\r\n\x1a\n")
+
+func TestExportEmbedCGolden(t *testing.T) {
+	f, err := ExportEmbed(goldenAtlas(), fakePNG, "c")
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	assertGoldenText(t, filepath.Join("embed", f.Name), f.Data)
+}
+
+func TestExportEmbedGoTypeChecks(t *testing.T) {
+	for _, mode := range []string{"go", "go-bytes"} {
+		f, err := ExportEmbed(goldenAtlas(), fakePNG, mode)
+		if err != nil {
+			t.Fatalf("%s: export failed: %v", mode, err)
+		}
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, f.Name, f.Data, parser.ParseComments)
+		if err != nil {
+			t.Fatalf("%s: parse failed: %v\n%s", mode, err, f.Data)
+		}
+		if mode == "go" {
+			if !strings.Contains(string(f.Data), "//go:embed atlas.png\nvar PNG []byte") {
+				t.Fatalf("missing go:embed directive:\n%s", f.Data)
+			}
+			continue
+		}
+		conf := types.Config{}
+		if _, err := conf.Check("atlas", fset, []*ast.File{file}, nil); err != nil {
+			t.Fatalf("type check failed: %v\n%s", err, f.Data)
+		}
+		if !strings.Contains(string(f.Data), "0x89, 0x50, 0x4e, 0x47") || !strings.Contains(string(f.Data), `{"coin", 10, 1, 2, 2, 0.5, 0.5},`) {
+			t.Fatalf("unexpected go-bytes output:\n%s", f.Data)
+		}
+	}
+}
+
+func TestExportEmbedValidation(t *testing.T) {
+	if _, err := ExportEmbed(goldenAtlas(), nil, "c"); err == nil {
+		t.Fatal("expected missing png error")
+	}
+	if _, err := ExportEmbed(goldenAtlas(), fakePNG, "rust"); err == nil {
+		t.Fatal("expected unsupported mode error")
+	}
+	if got := cString("a\"b\\é"); got != `"a\"b\\\303\251"` {
+		t.Fatalf("cString got %s", got)
+	}
+	f, err := ExportEmbed(model.Atlas{}, fakePNG, "c")
+	if err != nil {
+		t.Fatalf("empty atlas export failed: %v", err)
+	}
+	if !strings.Contains(string(f.Data), "pixelc_atlas_frames[1] = {{0}};") {
+		t.Fatalf("empty frame table not handled:\n%s", f.Data)
+	}
+}
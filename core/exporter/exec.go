@@ -0,0 +1,168 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"pixelc/core/anim"
+	"pixelc/internal/version"
+	"pixelc/pkg/schema"
+)
+
+// ExecPrefix marks a preset that runs an external exporter program.
+const ExecPrefix = "exec:"
+
+// DefaultPluginTimeout bounds a plugin run when the config sets none.
+const DefaultPluginTimeout = 30 * time.Second
+
+// reservedFiles are written by pixelc itself and cannot come from a plugin.
+// ".pixelc-cache.json" is the batch cache manifest.
+var reservedFiles = map[string]bool{"atlas.png": true, "report.json": true, ".pixelc-cache.json": true}
+
+type execExporter struct {
+	path string
+}
+
+// NewExecExporter returns an exporter that runs the program at path, sends
+// it a schema.PluginRequest on stdin and writes the files from the
+// schema.PluginResponse it prints on stdout.
+func NewExecExporter(path string) Exporter {
+	return execExporter{path: path}
+}
+
+func (e execExporter) Name() string { return ExecPrefix + e.path }
+
+// Files is unknown until the plugin runs.
+func (e execExporter) Files() []string { return nil }
+
+func (e execExporter) Options() []Option {
+	return []Option{{Name: "pluginTimeout", Type: "duration", Description: "maximum plugin run time"}}
+}
+
+func (e execExporter) Export(in Input) ([]File, error) {
+	req, err := pluginRequest(in)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshal plugin request: %w", err)
+	}
+	timeout := in.Config.PluginTimeout
+	if timeout <= 0 {
+		timeout = DefaultPluginTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, e.path)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = time.Second
+	runErr := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("plugin %s timed out after %s", e.path, timeout)
+	}
+	if runErr != nil {
+		if msg := lastLines(stderr.String(), 20); msg != "" {
+			return nil, fmt.Errorf("plugin %s failed: %v: %s", e.path, runErr, msg)
+		}
+		return nil, fmt.Errorf("plugin %s failed: %w", e.path, runErr)
+	}
+
+	var resp schema.PluginResponse
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("plugin %s: decode response: %w", e.path, err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("plugin %s: %s", e.path, resp.Error)
+	}
+	if resp.Version != schema.PluginProtocolVersion {
+		return nil, fmt.Errorf("plugin %s: unsupported protocol version %d", e.path, resp.Version)
+	}
+	if len(resp.Files) == 0 {
+		return nil, fmt.Errorf("plugin %s returned no files", e.path)
+	}
+	files := make([]File, 0, len(resp.Files))
+	for _, f := range resp.Files {
+		if f.Name == "" || f.Name != filepath.Base(f.Name) || strings.ContainsAny(f.Name, `/\`) || f.Name == "." || f.Name == ".." {
+			return nil, fmt.Errorf("plugin %s: invalid file name %q", e.path, f.Name)
+		}
+		if reservedFiles[f.Name] {
+			return nil, fmt.Errorf("plugin %s: file name %s is reserved", e.path, f.Name)
+		}
+		var data []byte
+		switch f.Encoding {
+		case "", "utf8":
+			data = []byte(f.Data)
+		case "base64":
+			if data, err = base64.StdEncoding.DecodeString(f.Data); err != nil {
+				return nil, fmt.Errorf("plugin %s: file %s: %w", e.path, f.Name, err)
+			}
+		default:
+			return nil, fmt.Errorf("plugin %s: file %s: unknown encoding %q", e.path, f.Name, f.Encoding)
+		}
+		files = append(files, File{Name: f.Name, Data: data})
+	}
+	return files, nil
+}
+
+func pluginRequest(in Input) (schema.PluginRequest, error) {
+	if err := in.Atlas.Validate(); err != nil {
+		return schema.PluginRequest{}, err
+	}
+	appVersion := in.Version
+	if appVersion == "" {
+		appVersion = version.Version
+	}
+	req := schema.PluginRequest{
+		Version: schema.PluginProtocolVersion, App: version.AppName, AppVersion: appVersion,
+		Pages:  []schema.PluginPage{{Image: in.ImageName, Width: in.Atlas.Width, Height: in.Atlas.Height}},
+		Frames: make([]schema.PluginFrame, 0, len(in.Atlas.Sprites)),
+	}
+	names := make([]string, 0, len(in.Atlas.Sprites))
+	for _, ps := range sortedSprites(in.Atlas) {
+		s := ps.Sprite
+		srcW, srcH := sourceSize(s)
+		f := schema.PluginFrame{
+			Name: s.Name, X: ps.AtlasX, Y: ps.AtlasY, W: s.Width, H: s.Height,
+			SourceWidth: srcW, SourceHeight: srcH, OffsetX: s.OffsetX, OffsetY: s.OffsetY,
+			PivotX: s.PivotX, PivotY: s.PivotY,
+		}
+		if b := s.Border; !b.IsZero() {
+			f.Border = &schema.UnityBorder{Left: b.Left, Top: b.Top, Right: b.Right, Bottom: b.Bottom}
+		}
+		for _, poly := range s.Polygons {
+			f.Polygons = append(f.Polygons, unityPoints(poly))
+		}
+		req.Frames = append(req.Frames, f)
+		names = append(names, s.Name)
+	}
+	anims, _, err := anim.BuildAnimations(names, in.FPS)
+	if err != nil {
+		return schema.PluginRequest{}, err
+	}
+	req.Animations = make([]schema.PluginAnimation, 0, len(anims))
+	for _, a := range anims {
+		req.Animations = append(req.Animations, schema.PluginAnimation{State: a.State, FPS: a.FPS, Frames: a.Frames})
+	}
+	return req, nil
+}
+
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
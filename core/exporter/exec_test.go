@@ -0,0 +1,115 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"pixelc/pkg/model"
+	"pixelc/pkg/schema"
+)
+
+// TestMain lets the test binary act as an exec: plugin when
+// PIXELC_TEST_PLUGIN names a behaviour.
+func TestMain(m *testing.M) {
+	if mode := os.Getenv("PIXELC_TEST_PLUGIN"); mode != "" {
+		os.Exit(runTestPlugin(mode))
+	}
+	os.Exit(m.Run())
+}
+
+func runTestPlugin(mode string) int {
+	var req schema.PluginRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fmt.Fprintln(os.Stderr, "bad request:", err)
+		return 2
+	}
+	resp := schema.PluginResponse{Version: schema.PluginProtocolVersion}
+	switch mode {
+	case "echo":
+		names := make([]string, 0, len(req.Frames))
+		for _, f := range req.Frames {
+			names = append(names, f.Name)
+		}
+		summary := fmt.Sprintf("v%d %s %dx%d anims=%d %s", req.Version, req.Pages[0].Image, req.Pages[0].Width, req.Pages[0].Height, len(req.Animations), strings.Join(names, ","))
+		resp.Files = []schema.PluginFile{
+			{Name: "atlas.engine", Data: summary},
+			{Name: "atlas.bin2", Encoding: "base64", Data: "AAEC"},
+		}
+	case "fail":
+		fmt.Fprintln(os.Stderr, "engine format exploded")
+		return 3
+	case "sleep":
+		time.Sleep(5 * time.Second)
+	case "error":
+		resp.Error = "unsupported sprite layout"
+	case "escape":
+		resp.Files = []schema.PluginFile{{Name: "../evil.txt", Data: "x"}}
+	case "reserved":
+		resp.Files = []schema.PluginFile{{Name: "atlas.png", Data: "x"}}
+	case "manifest":
+		resp.Files = []schema.PluginFile{{Name: ".pixelc-cache.json", Data: "{}"}}
+	}
+	if err := json.NewEncoder(os.Stdout).Encode(resp); err != nil {
+		return 2
+	}
+	return 0
+}
+
+func execInput(timeout time.Duration) Input {
+	return Input{Atlas: goldenAtlas(), ImageName: "atlas.png", Version: "1.0.0", FPS: 12, Config: model.Config{PluginTimeout: timeout}}
+}
+
+func TestExecExporterRoundTrip(t *testing.T) {
+	t.Setenv("PIXELC_TEST_PLUGIN", "echo")
+	e, err := Resolve(ExecPrefix + os.Args[0])
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	files, err := e.Export(execInput(0))
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if len(files) != 2 || files[0].Name != "atlas.engine" || files[1].Name != "atlas.bin2" {
+		t.Fatalf("unexpected files %+v", files)
+	}
+	if got := string(files[0].Data); got != "v1 atlas.png 16x8 anims=1 coin,hero_walk_001,hero_walk_002" {
+		t.Fatalf("unexpected plugin output %q", got)
+	}
+	if string(files[1].Data) != "\x00\x01\x02" {
+		t.Fatalf("base64 file not decoded: %v", files[1].Data)
+	}
+}
+
+func TestExecExporterErrors(t *testing.T) {
+	cases := []struct {
+		mode    string
+		timeout time.Duration
+		want    string
+	}{
+		{"fail", 0, "engine format exploded"},
+		{"sleep", 200 * time.Millisecond, "timed out after 200ms"},
+		{"error", 0, "unsupported sprite layout"},
+		{"escape", 0, "invalid file name"},
+		{"reserved", 0, "is reserved"},
+		{"manifest", 0, "is reserved"},
+	}
+	for _, tc := range cases {
+		t.Setenv("PIXELC_TEST_PLUGIN", tc.mode)
+		_, err := NewExecExporter(os.Args[0]).Export(execInput(tc.timeout))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%s: expected %q, got %v", tc.mode, tc.want, err)
+		}
+	}
+}
+
+func TestResolveExecRequiresProgram(t *testing.T) {
+	for _, name := range []string{"exec:", "exec:./does-not-exist"} {
+		if _, err := Resolve(name); err == nil {
+			t.Fatalf("expected %s to be rejected", name)
+		}
+	}
+}
@@ -11,7 +11,34 @@ import (
 	"pixelc/pkg/schema"
 )
 
+// File is one metadata file written next to the atlas image.
+type File struct {
+	Name string
+	Data []byte
+}
+
 func ExportUnity(atlas model.Atlas, atlasImageName string, appVersion string, fps int) ([]byte, error) {
+	return exportUnity(atlas, atlasImageName, appVersion, fps, 1, nil)
+}
+
+// Variant is one resolution of an atlas when Config.Scales adds any.
+type Variant struct {
+	Scale         float64
+	ImageName     string
+	Width, Height int
+}
+
+func schemaVariants(variants []Variant) []schema.Variant {
+	out := make([]schema.Variant, 0, len(variants))
+	for _, v := range variants {
+		out = append(out, schema.Variant{Scale: v.Scale, Image: v.ImageName, W: v.Width, H: v.Height})
+	}
+	return out
+}
+
+// exportUnity is ExportUnity for the variant at scale; variants, when set,
+// become meta.variants.
+func exportUnity(atlas model.Atlas, atlasImageName string, appVersion string, fps int, scale float64, variants []Variant) ([]byte, error) {
 	if err := atlas.Validate(); err != nil {
 		return nil, err
 	}
@@ -31,13 +58,12 @@ func ExportUnity(atlas model.Atlas, atlasImageName string, appVersion string, fp
 	out.Meta.Image = atlasImageName
 	out.Meta.Size.W = atlas.Width
 	out.Meta.Size.H = atlas.Height
+	if len(variants) > 0 {
+		out.Meta.Scale, out.Meta.Variants = scale, schemaVariants(variants)
+	}
 
 	names := make([]string, 0, len(atlas.Sprites))
-	ordered := make([]model.PlacedSprite, len(atlas.Sprites))
-	copy(ordered, atlas.Sprites)
-	sort.SliceStable(ordered, func(i, j int) bool {
-		return ordered[i].Sprite.Name < ordered[j].Sprite.Name
-	})
+	ordered := sortedSprites(atlas)
 
 	for _, ps := range ordered {
 		names = append(names, ps.Sprite.Name)
@@ -48,6 +74,15 @@ func ExportUnity(atlas model.Atlas, atlasImageName string, appVersion string, fp
 		f.Frame.H = ps.Sprite.Height
 		f.Pivot.X = ps.Sprite.PivotX
 		f.Pivot.Y = ps.Sprite.PivotY
+		if b := ps.Sprite.Border; !b.IsZero() {
+			f.Border = &schema.UnityBorder{Left: b.Left, Top: b.Top, Right: b.Right, Bottom: b.Bottom}
+		}
+		for _, poly := range ps.Sprite.Polygons {
+			f.Polygons = append(f.Polygons, unityPoints(poly))
+		}
+		if len(ps.Sprite.Mesh.Vertices) > 0 {
+			f.Mesh = unityMesh(ps, atlas.Width, atlas.Height)
+		}
 		out.Frames[ps.Sprite.Name] = f
 	}
 
@@ -68,3 +103,41 @@ func ExportUnity(atlas model.Atlas, atlasImageName string, appVersion string, fp
 	}
 	return b, nil
 }
+
+func unityMesh(ps model.PlacedSprite, atlasW, atlasH int) *schema.UnityMesh {
+	m := &schema.UnityMesh{Triangles: append([]int(nil), ps.Sprite.Mesh.Triangles...)}
+	for _, v := range ps.Sprite.Mesh.Vertices {
+		m.Vertices = append(m.Vertices, schema.UnityPoint{X: v.X, Y: v.Y})
+		m.UVs = append(m.UVs, schema.UnityPoint{
+			X: (float64(ps.AtlasX) + v.X) / float64(atlasW),
+			Y: (float64(ps.AtlasY) + v.Y) / float64(atlasH),
+		})
+	}
+	return m
+}
+
+func unityPoints(poly []model.Point) []schema.UnityPoint {
+	pts := make([]schema.UnityPoint, 0, len(poly))
+	for _, p := range poly {
+		pts = append(pts, schema.UnityPoint{X: p.X, Y: p.Y})
+	}
+	return pts
+}
+
+func sortedSprites(atlas model.Atlas) []model.PlacedSprite {
+	ordered := make([]model.PlacedSprite, len(atlas.Sprites))
+	copy(ordered, atlas.Sprites)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Sprite.Name < ordered[j].Sprite.Name
+	})
+	return ordered
+}
+
+// sourceSize returns the untrimmed canvas size, falling back to the frame
+// size for sprites that never went through trimming.
+func sourceSize(s model.Sprite) (int, int) {
+	if s.SourceWidth > 0 && s.SourceHeight > 0 {
+		return s.SourceWidth, s.SourceHeight
+	}
+	return s.Width, s.Height
+}
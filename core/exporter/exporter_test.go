@@ -49,6 +49,35 @@ func TestExportUnityDeterministic(t *testing.T) {
 	}
 }
 
+func TestExportUnityMesh(t *testing.T) {
+	m := model.Mesh{
+		Vertices:  []model.Point{{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 2, Y: 4}, {X: 0, Y: 4}},
+		Triangles: []int{0, 1, 2, 0, 2, 3},
+	}
+	atlas := model.Atlas{Width: 8, Height: 8, Sprites: []model.PlacedSprite{
+		{Sprite: model.Sprite{Name: "rock", Width: 2, Height: 4, Mesh: m}, AtlasX: 4, AtlasY: 2},
+		{Sprite: model.Sprite{Name: "plain", Width: 1, Height: 1}, AtlasX: 0, AtlasY: 0},
+	}}
+	b, err := ExportUnity(atlas, "atlas.png", "0.1.0", 12)
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	var out schema.UnityAtlasJSON
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	rock := out.Frames["rock"].Mesh
+	if rock == nil || len(rock.Triangles) != 6 || len(rock.UVs) != 4 {
+		t.Fatalf("mesh not exported: %s", b)
+	}
+	if rock.UVs[2] != (schema.UnityPoint{X: 0.75, Y: 0.75}) || rock.Vertices[2] != (schema.UnityPoint{X: 2, Y: 4}) {
+		t.Fatalf("unexpected mesh coordinates: %+v", rock)
+	}
+	if out.Frames["plain"].Mesh != nil {
+		t.Fatalf("unexpected mesh for plain sprite")
+	}
+}
+
 func TestExportUnityValidation(t *testing.T) {
 	_, err := ExportUnity(model.Atlas{Width: -1}, "atlas.png", "0.1.0", 12)
 	if err == nil {
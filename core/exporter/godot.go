@@ -0,0 +1,65 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"pixelc/core/anim"
+	"pixelc/pkg/model"
+	"pixelc/pkg/schema"
+)
+
+// ExportGodot writes atlas.godot.json, laid out for building AtlasTexture,
+// Sprite2D, SpriteFrames, StyleBoxTexture and CollisionPolygon2D resources
+// in Godot 4.
+func ExportGodot(atlas model.Atlas, atlasImageName string, fps int) ([]byte, error) {
+	if err := atlas.Validate(); err != nil {
+		return nil, err
+	}
+	if atlasImageName == "" {
+		return nil, fmt.Errorf("atlas image name is required")
+	}
+	if fps <= 0 {
+		fps = 12
+	}
+	out := schema.GodotAtlasJSON{Image: atlasImageName, Size: schema.GodotSize{W: atlas.Width, H: atlas.Height}, Frames: map[string]schema.GodotFrame{}}
+	names := make([]string, 0, len(atlas.Sprites))
+	for _, ps := range sortedSprites(atlas) {
+		s := ps.Sprite
+		names = append(names, s.Name)
+		srcW, srcH := sourceSize(s)
+		px, py := s.PivotX*float64(s.Width), s.PivotY*float64(s.Height)
+		f := schema.GodotFrame{
+			Region: schema.GodotRect{X: ps.AtlasX, Y: ps.AtlasY, W: s.Width, H: s.Height},
+			Margin: schema.GodotRect{X: s.OffsetX, Y: s.OffsetY, W: srcW - s.Width, H: srcH - s.Height},
+			Offset: [2]float64{-(float64(s.OffsetX) + px), -(float64(s.OffsetY) + py)},
+		}
+		if b := s.Border; !b.IsZero() {
+			f.StyleBox = &schema.GodotStyleBox{RegionRect: f.Region, TextureMarginLeft: b.Left, TextureMarginTop: b.Top, TextureMarginRight: b.Right, TextureMarginBottom: b.Bottom}
+		}
+		for _, poly := range s.Polygons {
+			pts := make([][2]float64, 0, len(poly))
+			for _, p := range poly {
+				pts = append(pts, [2]float64{p.X - px, p.Y - py})
+			}
+			f.Polygons = append(f.Polygons, pts)
+		}
+		out.Frames[s.Name] = f
+	}
+
+	anims, _, err := anim.BuildAnimations(names, fps)
+	if err != nil {
+		return nil, err
+	}
+	if len(anims) > 0 {
+		out.Animations = map[string]schema.GodotAnimation{}
+		for _, a := range anims {
+			out.Animations[a.State] = schema.GodotAnimation{Speed: a.FPS, Frames: a.Frames}
+		}
+	}
+	b, err := json.Marshal(out)
+	if err != nil {
+		return nil, fmt.Errorf("marshal godot json: %w", err)
+	}
+	return b, nil
+}
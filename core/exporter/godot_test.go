@@ -0,0 +1,29 @@
+package exporter
+
+import (
+	"path/filepath"
+	"testing"
+
+	"pixelc/pkg/model"
+)
+
+func godotAtlas() model.Atlas {
+	atlas := goldenAtlas()
+	atlas.Sprites[2].Sprite.Border = model.Border{Left: 1, Top: 1}
+	atlas.Sprites[1].Sprite.Polygons = [][]model.Point{{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 6}, {X: 0, Y: 6}}}
+	return atlas
+}
+
+func TestExportGodotGolden(t *testing.T) {
+	got, err := ExportGodot(godotAtlas(), "atlas.png", 10)
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	assertGolden(t, filepath.Join("godot", "atlas.godot.json"), got)
+}
+
+func TestExportGodotValidation(t *testing.T) {
+	if _, err := ExportGodot(goldenAtlas(), "", 12); err == nil {
+		t.Fatal("expected missing image name error")
+	}
+}
@@ -0,0 +1,50 @@
+package exporter
+
+import (
+	"bytes"
+	"fmt"
+
+	"pixelc/core/anim"
+	"pixelc/pkg/model"
+)
+
+// ExportLibGDX writes the libGDX TextureAtlas text format (also read by the
+// Spine runtimes). Region names drop a trailing frame number, which becomes
+// the region index so runtimes can rebuild animations with findRegions.
+func ExportLibGDX(atlas model.Atlas, atlasImageName string) ([]byte, error) {
+	if err := atlas.Validate(); err != nil {
+		return nil, err
+	}
+	if atlasImageName == "" {
+		return nil, fmt.Errorf("atlas image name is required")
+	}
+	var buf bytes.Buffer
+	writeLibGDXPage(&buf, atlas, atlasImageName)
+	return buf.Bytes(), nil
+}
+
+func writeLibGDXPage(buf *bytes.Buffer, atlas model.Atlas, imageName string) {
+	fmt.Fprintf(buf, "%s\n", imageName)
+	fmt.Fprintf(buf, "size:%d,%d\n", atlas.Width, atlas.Height)
+	fmt.Fprintf(buf, "format:RGBA8888\n")
+	fmt.Fprintf(buf, "filter:Nearest,Nearest\n")
+	fmt.Fprintf(buf, "repeat:none\n")
+	for _, ps := range sortedSprites(atlas) {
+		s := ps.Sprite
+		name, index := s.Name, -1
+		if p := anim.ParseFrameName(s.Name); p.Grouped {
+			name, index = p.Prefix, p.Index
+		}
+		srcW, srcH := sourceSize(s)
+		// libGDX offsets are measured from the bottom-left of the original image.
+		offsetY := srcH - s.OffsetY - s.Height
+		fmt.Fprintf(buf, "%s\n", name)
+		fmt.Fprintf(buf, "  bounds:%d,%d,%d,%d\n", ps.AtlasX, ps.AtlasY, s.Width, s.Height)
+		fmt.Fprintf(buf, "  offsets:%d,%d,%d,%d\n", s.OffsetX, offsetY, srcW, srcH)
+		fmt.Fprintf(buf, "  rotate:false\n")
+		if b := s.Border; !b.IsZero() {
+			fmt.Fprintf(buf, "  split:%d,%d,%d,%d\n", b.Left, b.Right, b.Top, b.Bottom)
+		}
+		fmt.Fprintf(buf, "  index:%d\n", index)
+	}
+}
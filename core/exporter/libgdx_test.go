@@ -0,0 +1,37 @@
+package exporter
+
+import (
+	"path/filepath"
+	"testing"
+
+	"pixelc/internal/testutil"
+	"pixelc/pkg/model"
+)
+
+func TestExportLibGDXGolden(t *testing.T) {
+	atlas := goldenAtlas()
+	atlas.Sprites = append(atlas.Sprites, model.PlacedSprite{
+		Sprite: model.Sprite{Name: "panel", Width: 4, Height: 4, Border: model.Border{Left: 1, Top: 1, Right: 2, Bottom: 1}},
+		AtlasX: 12, AtlasY: 1,
+	})
+	got, err := ExportLibGDX(atlas, "atlas.png")
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	want, err := testutil.ReadGolden(filepath.Join("..", "..", "fixtures", "golden", "libgdx", "atlas.atlas"))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if string(got) != string(want) {
+		t.Fatalf("libgdx atlas mismatch\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestExportLibGDXValidation(t *testing.T) {
+	if _, err := ExportLibGDX(model.Atlas{Width: -1}, "atlas.png"); err == nil {
+		t.Fatal("expected validation error")
+	}
+	if _, err := ExportLibGDX(goldenAtlas(), ""); err == nil {
+		t.Fatal("expected image name error")
+	}
+}
@@ -0,0 +1,219 @@
+package exporter
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"pixelc/pkg/model"
+)
+
+// Exporter turns a packed atlas into one or more metadata files.
+type Exporter interface {
+	Name() string
+	// Files lists the file names Export writes, primary metadata first.
+	Files() []string
+	// Options documents the config settings the exporter reads.
+	Options() []Option
+	Export(in Input) ([]File, error)
+}
+
+// Input is what every exporter receives.
+type Input struct {
+	Atlas     model.Atlas
+	ImageName string
+	Version   string
+	FPS       int
+	Config    model.Config
+	Scale     float64   // resolution of Atlas relative to the source; 0 means 1
+	Variants  []Variant // every resolution written when Config.Scales adds any
+}
+
+func (in Input) scale() float64 {
+	if in.Scale == 0 {
+		return 1
+	}
+	return in.Scale
+}
+
+// Option describes one config setting in an exporter's options schema.
+type Option struct {
+	Name        string
+	Type        string
+	Description string
+}
+
+type preset struct {
+	name    string
+	files   []string
+	options []Option
+	export  func(Input) ([]File, error)
+}
+
+func (p preset) Name() string                    { return p.name }
+func (p preset) Files() []string                 { return append([]string(nil), p.files...) }
+func (p preset) Options() []Option               { return append([]Option(nil), p.options...) }
+func (p preset) Export(in Input) ([]File, error) { return p.export(in) }
+
+// NewPreset builds an Exporter from a function.
+func NewPreset(name string, files []string, options []Option, export func(Input) ([]File, error)) Exporter {
+	return preset{name: name, files: files, options: options, export: export}
+}
+
+var (
+	registryMu    sync.RWMutex
+	registry      = map[string]Exporter{}
+	registryOrder []string
+)
+
+// Register adds an exporter so it can be named in model.Config.Preset.
+func Register(e Exporter) error {
+	name := e.Name()
+	if name == "" || strings.ContainsAny(name, ", ") || strings.HasPrefix(name, ExecPrefix) {
+		return fmt.Errorf("invalid exporter name %q", name)
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[name]; ok {
+		return fmt.Errorf("exporter %s already registered", name)
+	}
+	registry[name] = e
+	registryOrder = append(registryOrder, name)
+	return nil
+}
+
+func Lookup(name string) (Exporter, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	e, ok := registry[name]
+	return e, ok
+}
+
+// Registered returns every exporter in registration order.
+func Registered() []Exporter {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	out := make([]Exporter, 0, len(registryOrder))
+	for _, name := range registryOrder {
+		out = append(out, registry[name])
+	}
+	return out
+}
+
+// Resolve returns the registered exporter for name, or a plugin exporter for
+// an exec:<path> name whose program can be found.
+func Resolve(name string) (Exporter, error) {
+	if path, ok := strings.CutPrefix(name, ExecPrefix); ok {
+		if path == "" {
+			return nil, fmt.Errorf("preset %s: plugin path is required", name)
+		}
+		if _, err := exec.LookPath(path); err != nil {
+			return nil, fmt.Errorf("preset %s: %w", name, err)
+		}
+		return NewExecExporter(path), nil
+	}
+	e, ok := Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown preset %q (available: %s, or exec:<program>)", name, strings.Join(RegisteredNames(), ", "))
+	}
+	return e, nil
+}
+
+// ValidatePresets checks that every name resolves, is listed once, and that
+// no two presets declare the same file. Plugins declare no files, so their
+// collisions are caught when they run.
+func ValidatePresets(names []string) error {
+	if len(names) == 0 {
+		return fmt.Errorf("preset is required")
+	}
+	owner := map[string]string{}
+	seen := map[string]bool{}
+	for _, name := range names {
+		e, err := Resolve(name)
+		if err != nil {
+			return err
+		}
+		if seen[name] {
+			return fmt.Errorf("preset %s listed twice", name)
+		}
+		seen[name] = true
+		for _, f := range e.Files() {
+			if prev, ok := owner[f]; ok {
+				return fmt.Errorf("presets %s and %s both write %s", prev, name, f)
+			}
+			owner[f] = name
+		}
+	}
+	return nil
+}
+
+// RegisteredNames returns exporter names in registration order.
+func RegisteredNames() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return append([]string(nil), registryOrder...)
+}
+
+var fpsOption = Option{Name: "fps", Type: "int", Description: "animation frames per second"}
+
+func single(name string, export func(Input) ([]byte, error)) func(Input) ([]File, error) {
+	return func(in Input) ([]File, error) {
+		data, err := export(in)
+		if err != nil {
+			return nil, err
+		}
+		return []File{{Name: name, Data: data}}, nil
+	}
+}
+
+func init() {
+	builtins := []Exporter{
+		NewPreset("unity", []string{"atlas.json"}, []Option{fpsOption}, single("atlas.json", func(in Input) ([]byte, error) {
+			return exportUnity(in.Atlas, in.ImageName, in.Version, in.FPS, in.scale(), in.Variants)
+		})),
+		NewPreset("texturepacker-hash", []string{"atlas.json"}, nil, single("atlas.json", func(in Input) ([]byte, error) {
+			return exportTexturePackerHash(in.Atlas, in.ImageName, in.Version, in.scale(), in.Variants)
+		})),
+		NewPreset("texturepacker-array", []string{"atlas.json"}, nil, single("atlas.json", func(in Input) ([]byte, error) {
+			return exportTexturePackerArray(in.Atlas, in.ImageName, in.Version, in.scale(), in.Variants)
+		})),
+		NewPreset("libgdx", []string{"atlas.atlas"}, nil, single("atlas.atlas", func(in Input) ([]byte, error) {
+			return ExportLibGDX(in.Atlas, in.ImageName)
+		})),
+		NewPreset("starling", []string{"atlas.xml"}, nil, single("atlas.xml", func(in Input) ([]byte, error) {
+			return ExportStarling(in.Atlas, in.ImageName)
+		})),
+		NewPreset("cocos2d", []string{"atlas.plist"}, nil, single("atlas.plist", func(in Input) ([]byte, error) {
+			return ExportCocos2d(in.Atlas, in.ImageName)
+		})),
+		NewPreset("css", []string{"atlas.css", "preview.html"}, []Option{fpsOption}, func(in Input) ([]File, error) {
+			opts := CSSOptions{ImageName: in.ImageName}
+			if in.scale() == 1 {
+				for _, v := range in.Variants {
+					opts.Variants = append(opts.Variants, CSSVariant{Scale: v.Scale, ImageName: v.ImageName})
+				}
+			}
+			css, err := ExportCSS(in.Atlas, opts)
+			if err != nil {
+				return nil, err
+			}
+			page, err := ExportPreviewHTML(in.Atlas, strings.TrimSuffix(in.ImageName, ".png")+".css", in.FPS)
+			if err != nil {
+				return nil, err
+			}
+			return []File{{Name: "atlas.css", Data: css}, {Name: "preview.html", Data: page}}, nil
+		}),
+		NewPreset("godot", []string{"atlas.godot.json"}, []Option{fpsOption}, single("atlas.godot.json", func(in Input) ([]byte, error) {
+			return ExportGodot(in.Atlas, in.ImageName, in.FPS)
+		})),
+		NewPreset("binary", []string{"atlas.bin"}, []Option{fpsOption}, single("atlas.bin", func(in Input) ([]byte, error) {
+			return ExportBinary(in.Atlas, in.ImageName, in.FPS)
+		})),
+	}
+	for _, e := range builtins {
+		if err := Register(e); err != nil {
+			panic(err)
+		}
+	}
+}
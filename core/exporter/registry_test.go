@@ -0,0 +1,57 @@
+package exporter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistryBuiltins(t *testing.T) {
+	for _, name := range []string{"unity", "texturepacker-hash", "texturepacker-array", "libgdx", "starling", "cocos2d", "css", "binary"} {
+		e, ok := Lookup(name)
+		if !ok {
+			t.Fatalf("builtin preset %s not registered", name)
+		}
+		files, err := e.Export(Input{Atlas: goldenAtlas(), ImageName: "atlas.png", Version: "1.0.0", FPS: 12})
+		if err != nil {
+			t.Fatalf("%s export failed: %v", name, err)
+		}
+		want := e.Files()
+		if len(files) != len(want) {
+			t.Fatalf("%s wrote %d files, declares %d", name, len(files), len(want))
+		}
+		for i, f := range files {
+			if f.Name != want[i] {
+				t.Fatalf("%s file %d is %s, declared %s", name, i, f.Name, want[i])
+			}
+		}
+	}
+}
+
+func TestRegisterRejectsDuplicatesAndBadNames(t *testing.T) {
+	noop := func(Input) ([]File, error) { return nil, nil }
+	if err := Register(NewPreset("unity", []string{"x"}, nil, noop)); err == nil {
+		t.Fatal("expected duplicate registration error")
+	}
+	for _, name := range []string{"a,b", "exec:x"} {
+		if err := Register(NewPreset(name, []string{"x"}, nil, noop)); err == nil {
+			t.Fatalf("expected invalid name error for %s", name)
+		}
+	}
+}
+
+func TestValidatePresets(t *testing.T) {
+	if err := ValidatePresets([]string{"unity", "libgdx", "css"}); err != nil {
+		t.Fatalf("expected valid presets, got %v", err)
+	}
+	cases := map[string][]string{
+		"required":       nil,
+		"unknown preset": {"invalid"},
+		"listed twice":   {"unity", "unity"},
+		"both write":     {"unity", "texturepacker-hash"},
+	}
+	for want, names := range cases {
+		if err := ValidatePresets(names); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%v: expected %q error, got %v", names, want, err)
+		}
+	}
+}
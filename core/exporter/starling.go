@@ -0,0 +1,64 @@
+package exporter
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"pixelc/pkg/model"
+)
+
+type starlingAtlas struct {
+	XMLName     xml.Name             `xml:"TextureAtlas"`
+	ImagePath   string               `xml:"imagePath,attr"`
+	SubTextures []starlingSubTexture `xml:"SubTexture"`
+}
+
+type starlingSubTexture struct {
+	Name        string  `xml:"name,attr"`
+	X           int     `xml:"x,attr"`
+	Y           int     `xml:"y,attr"`
+	Width       int     `xml:"width,attr"`
+	Height      int     `xml:"height,attr"`
+	FrameX      *int    `xml:"frameX,attr"`
+	FrameY      *int    `xml:"frameY,attr"`
+	FrameWidth  *int    `xml:"frameWidth,attr"`
+	FrameHeight *int    `xml:"frameHeight,attr"`
+	PivotX      float64 `xml:"pivotX,attr"`
+	PivotY      float64 `xml:"pivotY,attr"`
+}
+
+// ExportStarling writes the Starling/Sparrow XML TextureAtlas format (also
+// read by Phaser). Trimmed sprites carry a negative frameX/frameY offset
+// into their untrimmed frame; pivots are in pixels of that frame.
+func ExportStarling(atlas model.Atlas, atlasImageName string) ([]byte, error) {
+	if err := atlas.Validate(); err != nil {
+		return nil, err
+	}
+	if atlasImageName == "" {
+		return nil, fmt.Errorf("atlas image name is required")
+	}
+	out := starlingAtlas{ImagePath: atlasImageName}
+	for _, ps := range sortedSprites(atlas) {
+		s := ps.Sprite
+		srcW, srcH := sourceSize(s)
+		st := starlingSubTexture{
+			Name:   s.Name,
+			X:      ps.AtlasX,
+			Y:      ps.AtlasY,
+			Width:  s.Width,
+			Height: s.Height,
+			PivotX: float64(s.OffsetX) + s.PivotX*float64(s.Width),
+			PivotY: float64(s.OffsetY) + s.PivotY*float64(s.Height),
+		}
+		if srcW != s.Width || srcH != s.Height {
+			fx, fy := -s.OffsetX, -s.OffsetY
+			st.FrameX, st.FrameY, st.FrameWidth, st.FrameHeight = &fx, &fy, &srcW, &srcH
+		}
+		out.SubTextures = append(out.SubTextures, st)
+	}
+	b, err := xml.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal starling xml: %w", err)
+	}
+	return append(append([]byte(xml.Header), b...), '\n'), nil
+}
@@ -0,0 +1,113 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"pixelc/core/anim"
+	"pixelc/internal/version"
+	"pixelc/pkg/model"
+	"pixelc/pkg/schema"
+)
+
+func ExportTexturePackerHash(atlas model.Atlas, atlasImageName string, appVersion string) ([]byte, error) {
+	return exportTexturePackerHash(atlas, atlasImageName, appVersion, 1, nil)
+}
+
+func exportTexturePackerHash(atlas model.Atlas, atlasImageName string, appVersion string, scale float64, variants []Variant) ([]byte, error) {
+	ordered, meta, anims, err := texturePackerParts(atlas, atlasImageName, appVersion, scale, variants)
+	if err != nil {
+		return nil, err
+	}
+	out := schema.TPHashJSON{Frames: map[string]schema.TPFrame{}, Animations: anims, Meta: meta}
+	for _, ps := range ordered {
+		out.Frames[ps.Sprite.Name] = texturePackerFrame(ps)
+	}
+	b, err := json.Marshal(out)
+	if err != nil {
+		return nil, fmt.Errorf("marshal texturepacker json: %w", err)
+	}
+	return b, nil
+}
+
+func ExportTexturePackerArray(atlas model.Atlas, atlasImageName string, appVersion string) ([]byte, error) {
+	return exportTexturePackerArray(atlas, atlasImageName, appVersion, 1, nil)
+}
+
+func exportTexturePackerArray(atlas model.Atlas, atlasImageName string, appVersion string, scale float64, variants []Variant) ([]byte, error) {
+	ordered, meta, anims, err := texturePackerParts(atlas, atlasImageName, appVersion, scale, variants)
+	if err != nil {
+		return nil, err
+	}
+	out := schema.TPArrayJSON{Frames: make([]schema.TPArrayFrame, 0, len(ordered)), Animations: anims, Meta: meta}
+	for _, ps := range ordered {
+		out.Frames = append(out.Frames, schema.TPArrayFrame{Filename: ps.Sprite.Name, TPFrame: texturePackerFrame(ps)})
+	}
+	b, err := json.Marshal(out)
+	if err != nil {
+		return nil, fmt.Errorf("marshal texturepacker json: %w", err)
+	}
+	return b, nil
+}
+
+// texturePackerParts builds what both layouts share. meta.scale is the
+// variant's scale and meta.variants lists every resolution, if any.
+func texturePackerParts(atlas model.Atlas, atlasImageName string, appVersion string, scale float64, variants []Variant) ([]model.PlacedSprite, schema.TPMeta, map[string][]string, error) {
+	if err := atlas.Validate(); err != nil {
+		return nil, schema.TPMeta{}, nil, err
+	}
+	if atlasImageName == "" {
+		return nil, schema.TPMeta{}, nil, fmt.Errorf("atlas image name is required")
+	}
+	if appVersion == "" {
+		appVersion = version.Version
+	}
+	meta := schema.TPMeta{
+		App:     version.AppName,
+		Version: appVersion,
+		Image:   atlasImageName,
+		Format:  "RGBA8888",
+		Size:    schema.TPSize{W: atlas.Width, H: atlas.Height},
+		Scale:   strconv.FormatFloat(scale, 'g', -1, 64),
+	}
+	if len(variants) > 0 {
+		meta.Variants = schemaVariants(variants)
+	}
+
+	ordered := sortedSprites(atlas)
+	names := make([]string, 0, len(ordered))
+	for _, ps := range ordered {
+		names = append(names, ps.Sprite.Name)
+	}
+	built, _, err := anim.BuildAnimations(names, 12)
+	if err != nil {
+		return nil, schema.TPMeta{}, nil, err
+	}
+	var anims map[string][]string
+	if len(built) > 0 {
+		anims = map[string][]string{}
+		for _, a := range built {
+			anims[a.State] = a.Frames
+		}
+	}
+	return ordered, meta, anims, nil
+}
+
+// texturePackerFrame maps a placed sprite to TexturePacker conventions:
+// spriteSourceSize is the trimmed rect inside the untrimmed canvas and the
+// pivot is normalized against that canvas.
+func texturePackerFrame(ps model.PlacedSprite) schema.TPFrame {
+	s := ps.Sprite
+	srcW, srcH := sourceSize(s)
+	return schema.TPFrame{
+		Frame:            schema.TPRect{X: ps.AtlasX, Y: ps.AtlasY, W: s.Width, H: s.Height},
+		Trimmed:          srcW != s.Width || srcH != s.Height,
+		SpriteSourceSize: schema.TPRect{X: s.OffsetX, Y: s.OffsetY, W: s.Width, H: s.Height},
+		SourceSize:       schema.TPSize{W: srcW, H: srcH},
+		Pivot: schema.TPPoint{
+			X: (float64(s.OffsetX) + s.PivotX*float64(s.Width)) / float64(srcW),
+			Y: (float64(s.OffsetY) + s.PivotY*float64(s.Height)) / float64(srcH),
+		},
+	}
+}
@@ -0,0 +1,70 @@
+package exporter
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pixelc/internal/testutil"
+	"pixelc/pkg/model"
+)
+
+func goldenAtlas() model.Atlas {
+	return model.Atlas{Width: 16, Height: 8, Sprites: []model.PlacedSprite{
+		{Sprite: model.Sprite{Name: "hero_walk_002", Width: 3, Height: 4, SourceWidth: 8, SourceHeight: 8, OffsetX: 2, OffsetY: 4, PivotX: 0.5, PivotY: 1}, AtlasX: 5, AtlasY: 1},
+		{Sprite: model.Sprite{Name: "hero_walk_001", Width: 4, Height: 6, SourceWidth: 8, SourceHeight: 8, OffsetX: 1, OffsetY: 2, PivotX: 0.5, PivotY: 1}, AtlasX: 1, AtlasY: 1},
+		{Sprite: model.Sprite{Name: "coin", Width: 2, Height: 2, PivotX: 0.5, PivotY: 0.5}, AtlasX: 10, AtlasY: 1},
+	}}
+}
+
+func TestExportTexturePackerGolden(t *testing.T) {
+	cases := []struct {
+		golden string
+		export func(model.Atlas, string, string) ([]byte, error)
+	}{
+		{"hash.json", ExportTexturePackerHash},
+		{"array.json", ExportTexturePackerArray},
+	}
+	for _, tc := range cases {
+		got, err := tc.export(goldenAtlas(), "atlas.png", "1.0.0")
+		if err != nil {
+			t.Fatalf("%s: export failed: %v", tc.golden, err)
+		}
+		assertGolden(t, filepath.Join("texturepacker", tc.golden), got)
+	}
+}
+
+func TestExportTexturePackerValidation(t *testing.T) {
+	if _, err := ExportTexturePackerHash(model.Atlas{Width: -1}, "atlas.png", "1.0.0"); err == nil {
+		t.Fatal("expected validation error")
+	}
+	if _, err := ExportTexturePackerArray(goldenAtlas(), "", "1.0.0"); err == nil {
+		t.Fatal("expected image name error")
+	}
+}
+
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	want, err := testutil.ReadGolden(filepath.Join("..", "..", "fixtures", "golden", name))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	eq, err := testutil.EqualJSON(got, want)
+	if err != nil {
+		t.Fatalf("compare %s: %v", name, err)
+	}
+	if !eq {
+		t.Fatalf("%s mismatch\ngot:  %s\nwant: %s", name, got, want)
+	}
+}
+
+func TestExportTexturePackerVariants(t *testing.T) {
+	variants := []Variant{{Scale: 1, ImageName: "atlas.png", Width: 16, Height: 8}, {Scale: 0.5, ImageName: "atlas@0.5x.png", Width: 8, Height: 4}}
+	got, err := exportTexturePackerArray(goldenAtlas(), "atlas@0.5x.png", "1.0.0", 0.5, variants)
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if !strings.Contains(string(got), `"scale":"0.5","variants":[{"scale":1,"image":"atlas.png","w":16,"h":8},{"scale":0.5,"image":"atlas@0.5x.png","w":8,"h":4}]`) {
+		t.Fatalf("unexpected meta: %s", got)
+	}
+}
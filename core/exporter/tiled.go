@@ -0,0 +1,196 @@
+package exporter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"image"
+	"strconv"
+
+	"pixelc/core/anim"
+	"pixelc/internal/imageutil"
+	"pixelc/pkg/model"
+)
+
+type TiledOptions struct {
+	Mode      string // "collection" or "grid"
+	Name      string
+	ImageName string // atlas image referenced by collection tiles
+	FPS       int
+	Spacing   int // grid mode only
+	Margin    int // grid mode only
+}
+
+type tiledTileset struct {
+	XMLName      xml.Name    `xml:"tileset"`
+	Version      string      `xml:"version,attr"`
+	TiledVersion string      `xml:"tiledversion,attr"`
+	Name         string      `xml:"name,attr"`
+	TileWidth    int         `xml:"tilewidth,attr"`
+	TileHeight   int         `xml:"tileheight,attr"`
+	Spacing      int         `xml:"spacing,attr,omitempty"`
+	Margin       int         `xml:"margin,attr,omitempty"`
+	TileCount    int         `xml:"tilecount,attr"`
+	Columns      int         `xml:"columns,attr"`
+	Grid         *tiledGrid  `xml:"grid"`
+	Image        *tiledImage `xml:"image"`
+	Tiles        []tiledTile `xml:"tile"`
+}
+
+type tiledGrid struct {
+	Orientation string `xml:"orientation,attr"`
+	Width       int    `xml:"width,attr"`
+	Height      int    `xml:"height,attr"`
+}
+
+type tiledImage struct {
+	Source string `xml:"source,attr"`
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+}
+
+type tiledTile struct {
+	ID         int             `xml:"id,attr"`
+	X          *int            `xml:"x,attr"`
+	Y          *int            `xml:"y,attr"`
+	Width      *int            `xml:"width,attr"`
+	Height     *int            `xml:"height,attr"`
+	Properties []tiledProperty `xml:"properties>property"`
+	Image      *tiledImage     `xml:"image"`
+	Animation  *tiledAnimation `xml:"animation"`
+}
+
+type tiledAnimation struct {
+	Frames []tiledFrame `xml:"frame"`
+}
+
+type tiledProperty struct {
+	Name  string `xml:"name,attr"`
+	Type  string `xml:"type,attr,omitempty"`
+	Value string `xml:"value,attr"`
+}
+
+type tiledFrame struct {
+	TileID   int `xml:"tileid,attr"`
+	Duration int `xml:"duration,attr"`
+}
+
+// ExportTiledTileset writes a Tiled .tsx tileset for the atlas sprites,
+// ordered by name. "collection" tiles point at their rect inside the atlas
+// image; "grid" renders every sprite's untrimmed canvas into a separate
+// tileset.png, which requires all canvases to share one size.
+func ExportTiledTileset(atlas model.Atlas, opts TiledOptions) ([]File, error) {
+	if err := atlas.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.Name == "" {
+		return nil, fmt.Errorf("tileset name is required")
+	}
+	if opts.FPS <= 0 {
+		opts.FPS = 12
+	}
+	ordered := sortedSprites(atlas)
+	ts := tiledTileset{Version: "1.10", TiledVersion: "1.10.2", Name: opts.Name, TileCount: len(ordered)}
+	var files []File
+
+	switch opts.Mode {
+	case "collection":
+		if opts.ImageName == "" {
+			return nil, fmt.Errorf("atlas image name is required")
+		}
+		ts.Grid = &tiledGrid{Orientation: "orthogonal", Width: 1, Height: 1}
+		for i, ps := range ordered {
+			x, y, w, h := ps.AtlasX, ps.AtlasY, ps.Sprite.Width, ps.Sprite.Height
+			ts.TileWidth = max(ts.TileWidth, w)
+			ts.TileHeight = max(ts.TileHeight, h)
+			ts.Tiles = append(ts.Tiles, tiledTile{ID: i, X: &x, Y: &y, Width: &w, Height: &h,
+				Image: &tiledImage{Source: opts.ImageName, Width: atlas.Width, Height: atlas.Height}})
+		}
+	case "grid":
+		img, err := tiledGridImage(ordered, &ts, opts)
+		if err != nil {
+			return nil, err
+		}
+		data, err := imageutil.EncodePNG(img)
+		if err != nil {
+			return nil, err
+		}
+		ts.Image = &tiledImage{Source: "tileset.png", Width: img.Bounds().Dx(), Height: img.Bounds().Dy()}
+		for i := range ordered {
+			ts.Tiles = append(ts.Tiles, tiledTile{ID: i})
+		}
+		files = append(files, File{Name: "tileset.png", Data: data})
+	default:
+		return nil, fmt.Errorf("unsupported tileset mode: %s", opts.Mode)
+	}
+
+	ids := map[string]int{}
+	names := make([]string, 0, len(ordered))
+	for i, ps := range ordered {
+		ids[ps.Sprite.Name] = i
+		names = append(names, ps.Sprite.Name)
+		ts.Tiles[i].Properties = tiledProperties(ps.Sprite.Name)
+	}
+	anims, _, err := anim.BuildAnimations(names, opts.FPS)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range anims {
+		ta := &tiledAnimation{}
+		for _, f := range a.Frames {
+			ta.Frames = append(ta.Frames, tiledFrame{TileID: ids[f], Duration: 1000 / a.FPS})
+		}
+		ts.Tiles[ids[a.Frames[0]]].Animation = ta
+	}
+
+	b, err := xml.MarshalIndent(ts, "", " ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal tiled tileset: %w", err)
+	}
+	tsx := File{Name: "atlas.tsx", Data: append(append([]byte(xml.Header), b...), '\n')}
+	return append([]File{tsx}, files...), nil
+}
+
+func tiledProperties(name string) []tiledProperty {
+	props := []tiledProperty{{Name: "name", Value: name}}
+	if p := anim.ParseFrameName(name); p.Grouped {
+		props = append(props,
+			tiledProperty{Name: "state", Value: p.State},
+			tiledProperty{Name: "frame", Type: "int", Value: strconv.Itoa(p.Index)})
+	}
+	return props
+}
+
+func tiledGridImage(ordered []model.PlacedSprite, ts *tiledTileset, opts TiledOptions) (*image.RGBA, error) {
+	if len(ordered) == 0 {
+		return nil, fmt.Errorf("grid tileset needs at least one sprite")
+	}
+	tw, th := sourceSize(ordered[0].Sprite)
+	for _, ps := range ordered {
+		if w, h := sourceSize(ps.Sprite); w != tw || h != th {
+			return nil, fmt.Errorf("grid tileset needs equal tile sizes: %s is %dx%d, expected %dx%d", ps.Sprite.Name, w, h, tw, th)
+		}
+	}
+	cols := 1
+	for cols*cols < len(ordered) {
+		cols++
+	}
+	rows := (len(ordered) + cols - 1) / cols
+	ts.TileWidth, ts.TileHeight = tw, th
+	ts.Spacing, ts.Margin, ts.Columns = opts.Spacing, opts.Margin, cols
+
+	w := 2*opts.Margin + cols*tw + (cols-1)*opts.Spacing
+	h := 2*opts.Margin + rows*th + (rows-1)*opts.Spacing
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i, ps := range ordered {
+		s := ps.Sprite
+		if s.Image == nil {
+			return nil, fmt.Errorf("sprite %s has no image", s.Name)
+		}
+		x := opts.Margin + (i%cols)*(tw+opts.Spacing) + s.OffsetX
+		y := opts.Margin + (i/cols)*(th+opts.Spacing) + s.OffsetY
+		if err := imageutil.Blit(img, s.Image, x, y); err != nil {
+			return nil, err
+		}
+	}
+	return img, nil
+}
@@ -0,0 +1,55 @@
+package exporter
+
+import (
+	"image"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pixelc/pkg/model"
+)
+
+func TestExportTiledCollectionGolden(t *testing.T) {
+	files, err := ExportTiledTileset(goldenAtlas(), TiledOptions{Mode: "collection", Name: "atlas", ImageName: "atlas.png", FPS: 10})
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if len(files) != 1 || files[0].Name != "atlas.tsx" {
+		t.Fatalf("unexpected files: %+v", files)
+	}
+	assertGoldenText(t, filepath.Join("tiled", "collection.tsx"), files[0].Data)
+}
+
+func TestExportTiledGrid(t *testing.T) {
+	tile := func(name string, x int) model.PlacedSprite {
+		img := image.NewRGBA(image.Rect(0, 0, 2, 4))
+		return model.PlacedSprite{Sprite: model.Sprite{Name: name, Image: img, Width: 2, Height: 4, SourceWidth: 4, SourceHeight: 4, OffsetX: 1}, AtlasX: x}
+	}
+	atlas := model.Atlas{Width: 16, Height: 4, Sprites: []model.PlacedSprite{tile("water_001", 0), tile("water_002", 4), tile("grass", 8)}}
+	files, err := ExportTiledTileset(atlas, TiledOptions{Mode: "grid", Name: "tiles", Spacing: 1, Margin: 2})
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if len(files) != 2 || files[1].Name != "tileset.png" {
+		t.Fatalf("unexpected files: %+v", files)
+	}
+	tsx := string(files[0].Data)
+	for _, want := range []string{
+		`tilewidth="4" tileheight="4" spacing="1" margin="2" tilecount="3" columns="2"`,
+		`<image source="tileset.png" width="13" height="13"></image>`,
+		`<frame tileid="1" duration="83"></frame>`,
+		`<property name="frame" type="int" value="2"></property>`,
+	} {
+		if !strings.Contains(tsx, want) {
+			t.Fatalf("tsx missing %q:\n%s", want, tsx)
+		}
+	}
+
+	atlas.Sprites[0].Sprite.SourceWidth = 8
+	if _, err := ExportTiledTileset(atlas, TiledOptions{Mode: "grid", Name: "tiles"}); err == nil {
+		t.Fatal("expected unequal tile size error")
+	}
+	if _, err := ExportTiledTileset(atlas, TiledOptions{Mode: "hex", Name: "tiles"}); err == nil {
+		t.Fatal("expected unsupported mode error")
+	}
+}
@@ -0,0 +1,155 @@
+package exporter
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"pixelc/pkg/model"
+)
+
+var plainYAML = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.\-]*$`)
+
+// ExportUnityMeta writes an atlas.png.meta TextureImporter so Unity imports
+// the atlas as a Multiple-mode sprite sheet without editor scripts. The
+// asset guid is derived from guidSeed and each spriteID/internalID from the
+// sprite name, so regenerated metas keep existing references intact.
+func ExportUnityMeta(atlas model.Atlas, guidSeed string) ([]byte, error) {
+	if err := atlas.Validate(); err != nil {
+		return nil, err
+	}
+	if guidSeed == "" {
+		return nil, fmt.Errorf("guid seed is required")
+	}
+	ordered := sortedSprites(atlas)
+	var buf bytes.Buffer
+	w := func(format string, args ...any) { fmt.Fprintf(&buf, format+"\n", args...) }
+
+	w("fileFormatVersion: 2")
+	w("guid: %s", stableHex("pixelc-atlas:"+guidSeed))
+	w("TextureImporter:")
+	w("  internalIDToNameTable: []")
+	w("  externalObjects: {}")
+	w("  serializedVersion: 12")
+	w("  mipmaps:")
+	w("    mipMapMode: 0")
+	w("    enableMipMap: 0")
+	w("    sRGBTexture: 1")
+	w("    linearTexture: 0")
+	w("  isReadable: 0")
+	w("  textureFormat: 1")
+	w("  maxTextureSize: 2048")
+	w("  textureSettings:")
+	w("    serializedVersion: 2")
+	w("    filterMode: 0")
+	w("    aniso: 1")
+	w("    mipBias: 0")
+	w("    wrapU: 1")
+	w("    wrapV: 1")
+	w("    wrapW: 1")
+	w("  nPOTScale: 0")
+	w("  lightmap: 0")
+	w("  compressionQuality: 50")
+	w("  spriteMode: 2")
+	w("  spriteExtrude: 1")
+	w("  spriteMeshType: 0")
+	w("  alignment: 0")
+	w("  spritePivot: {x: 0.5, y: 0.5}")
+	w("  spritePixelsToUnits: 100")
+	w("  spriteBorder: {x: 0, y: 0, z: 0, w: 0}")
+	w("  spriteGenerateFallbackPhysicsShape: 1")
+	w("  alphaUsage: 1")
+	w("  alphaIsTransparency: 1")
+	w("  textureType: 8")
+	w("  textureShape: 1")
+	w("  platformSettings:")
+	w("  - serializedVersion: 3")
+	w("    buildTarget: DefaultTexturePlatform")
+	w("    maxTextureSize: 2048")
+	w("    resizeAlgorithm: 0")
+	w("    textureFormat: -1")
+	w("    textureCompression: 0")
+	w("    compressionQuality: 50")
+	w("    crunchedCompression: 0")
+	w("    allowsAlphaSplitting: 0")
+	w("    overridden: 0")
+	w("  spriteSheet:")
+	w("    serializedVersion: 2")
+	if len(ordered) == 0 {
+		w("    sprites: []")
+	} else {
+		w("    sprites:")
+	}
+	for _, ps := range ordered {
+		s := ps.Sprite
+		b := s.Border
+		w("    - serializedVersion: 2")
+		w("      name: %s", yamlString(s.Name))
+		w("      rect:")
+		w("        serializedVersion: 2")
+		w("        x: %d", ps.AtlasX)
+		// Unity rects start at the bottom-left of the texture.
+		w("        y: %d", atlas.Height-ps.AtlasY-s.Height)
+		w("        width: %d", s.Width)
+		w("        height: %d", s.Height)
+		w("      alignment: 9")
+		w("      pivot: {x: %s, y: %s}", fmtFloat(s.PivotX), fmtFloat(1-s.PivotY))
+		w("      border: {x: %d, y: %d, z: %d, w: %d}", b.Left, b.Bottom, b.Right, b.Top)
+		w("      outline: []")
+		w("      physicsShape: []")
+		w("      tessellationDetail: 0")
+		w("      bones: []")
+		w("      spriteID: %s", stableHex("pixelc-sprite:"+s.Name))
+		w("      internalID: %d", stableInternalID(s.Name))
+		w("      vertices: []")
+		w("      indices: ")
+		w("      edges: []")
+		w("      weights: []")
+	}
+	w("    outline: []")
+	w("    physicsShape: []")
+	w("    bones: []")
+	w("    spriteID: ")
+	w("    internalID: 0")
+	w("    vertices: []")
+	w("    indices: ")
+	w("    edges: []")
+	w("    weights: []")
+	w("    secondaryTextures: []")
+	if len(ordered) == 0 {
+		w("    nameFileIdTable: {}")
+	} else {
+		w("    nameFileIdTable:")
+	}
+	for _, ps := range ordered {
+		w("      %s: %d", yamlString(ps.Sprite.Name), stableInternalID(ps.Sprite.Name))
+	}
+	w("  spritePackingTag: ")
+	w("  pSDRemoveMatte: 0")
+	w("  userData: ")
+	w("  assetBundleName: ")
+	w("  assetBundleVariant: ")
+	return buf.Bytes(), nil
+}
+
+func stableHex(seed string) string {
+	h := sha256.Sum256([]byte(seed))
+	return hex.EncodeToString(h[:16])
+}
+
+// stableInternalID derives a positive int64 file ID from the sprite name.
+func stableInternalID(name string) int64 {
+	h := sha256.Sum256([]byte("pixelc-internal:" + name))
+	return int64(binary.LittleEndian.Uint64(h[:8]) >> 1)
+}
+
+func yamlString(s string) string {
+	if plainYAML.MatchString(s) {
+		return s
+	}
+	return strconv.Quote(s)
+}
@@ -0,0 +1,52 @@
+package exporter
+
+import (
+	"strings"
+	"testing"
+
+	"pixelc/pkg/model"
+)
+
+func TestExportUnityMeta(t *testing.T) {
+	atlas := goldenAtlas()
+	atlas.Sprites = append(atlas.Sprites, model.PlacedSprite{
+		Sprite: model.Sprite{Name: "ui panel", Width: 4, Height: 4, PivotX: 0.5, PivotY: 0.5, Border: model.Border{Left: 1, Top: 2, Right: 3, Bottom: 1}},
+		AtlasX: 12, AtlasY: 2,
+	})
+	b1, err := ExportUnityMeta(atlas, "characters/hero")
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	meta := string(b1)
+	for _, want := range []string{
+		"guid: " + stableHex("pixelc-atlas:characters/hero") + "\n",
+		"  spriteMode: 2\n",
+		"    filterMode: 0\n",
+		"    textureCompression: 0\n",
+		// hero_walk_001 sits at atlas y=1 with height 6 in a 16x8 atlas
+		"      name: hero_walk_001\n      rect:\n        serializedVersion: 2\n        x: 1\n        y: 1\n        width: 4\n        height: 6\n",
+		"      pivot: {x: 0.5, y: 0}\n",
+		"      name: \"ui panel\"\n",
+		"      border: {x: 1, y: 1, z: 3, w: 2}\n",
+		"      spriteID: " + stableHex("pixelc-sprite:coin") + "\n",
+	} {
+		if !strings.Contains(meta, want) {
+			t.Fatalf("meta missing %q:\n%s", want, meta)
+		}
+	}
+
+	// reordering sprites or moving them must not change their IDs
+	atlas.Sprites[0], atlas.Sprites[1] = atlas.Sprites[1], atlas.Sprites[0]
+	atlas.Sprites[2].AtlasX = 11
+	b2, err := ExportUnityMeta(atlas, "characters/hero")
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if strings.Count(string(b2), stableHex("pixelc-sprite:hero_walk_002")) != 1 || string(b1) == string(b2) {
+		t.Fatalf("sprite ids not stable across layout changes")
+	}
+
+	if _, err := ExportUnityMeta(atlas, ""); err == nil {
+		t.Fatal("expected guid seed error")
+	}
+}
@@ -0,0 +1,56 @@
+package exporter
+
+import (
+	"encoding/xml"
+	"path/filepath"
+	"testing"
+
+	"pixelc/internal/testutil"
+	"pixelc/pkg/model"
+)
+
+func TestExportStarlingGolden(t *testing.T) {
+	got, err := ExportStarling(goldenAtlas(), "atlas.png")
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	assertGoldenText(t, filepath.Join("starling", "atlas.xml"), got)
+	var parsed starlingAtlas
+	if err := xml.Unmarshal(got, &parsed); err != nil {
+		t.Fatalf("invalid xml: %v", err)
+	}
+}
+
+func TestExportCocos2dGolden(t *testing.T) {
+	got, err := ExportCocos2d(goldenAtlas(), "atlas.png")
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	assertGoldenText(t, filepath.Join("cocos2d", "atlas.plist"), got)
+	var v struct{}
+	if err := xml.Unmarshal(got, &v); err != nil {
+		t.Fatalf("invalid plist xml: %v", err)
+	}
+}
+
+func TestExportXMLFormatsValidation(t *testing.T) {
+	for name, export := range map[string]func(model.Atlas, string) ([]byte, error){"starling": ExportStarling, "cocos2d": ExportCocos2d} {
+		if _, err := export(model.Atlas{Width: -1}, "atlas.png"); err == nil {
+			t.Fatalf("%s: expected validation error", name)
+		}
+		if _, err := export(goldenAtlas(), ""); err == nil {
+			t.Fatalf("%s: expected image name error", name)
+		}
+	}
+}
+
+func assertGoldenText(t *testing.T, name string, got []byte) {
+	t.Helper()
+	want, err := testutil.ReadGolden(filepath.Join("..", "..", "fixtures", "golden", name))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if string(got) != string(want) {
+		t.Fatalf("%s mismatch\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
@@ -0,0 +1,327 @@
+package mesh
+
+import (
+	"container/heap"
+	"fmt"
+	"image"
+	"math"
+
+	"pixelc/core/collider"
+	"pixelc/pkg/model"
+)
+
+// Build triangulates the outer alpha outlines of img, the same outlines
+// collider.Build traces, with one shared vertex per outline corner. While
+// the mesh is over maxVertices, the corner whose removal adds the least
+// area goes: a concave corner is cut off, or two convex corners are replaced
+// by the point where their neighbouring edges meet. Both only grow the
+// outline, so every opaque pixel stays covered and the vertices left are the
+// contour detail that is cheapest to keep. Holes are covered. When no corner
+// can go the mesh is the bounding quad of the opaque pixels.
+func Build(img *image.RGBA, maxVertices int) (model.Mesh, error) {
+	if img == nil {
+		return model.Mesh{}, fmt.Errorf("sprite image is nil")
+	}
+	if maxVertices < 4 {
+		return model.Mesh{}, fmt.Errorf("mesh max vertices must be >= 4")
+	}
+
+	outlines, err := collider.Build(img, 0, false)
+	if err != nil {
+		return model.Mesh{}, err
+	}
+	if vertexCount(outlines) > maxVertices {
+		r := newReducer(outlines, float64(img.Bounds().Dx()), float64(img.Bounds().Dy()))
+		// Every outline keeps at least a triangle.
+		if len(outlines)*3 <= maxVertices && r.reduce(maxVertices) {
+			outlines = r.outlines()
+		} else {
+			outlines = [][]model.Point{boundingQuad(outlines)}
+		}
+	}
+
+	m := model.Mesh{}
+	for _, poly := range outlines {
+		base := len(m.Vertices)
+		m.Vertices = append(m.Vertices, poly...)
+		for _, t := range collider.Triangulate(poly) {
+			m.Triangles = append(m.Triangles, base+t[0], base+t[1], base+t[2])
+		}
+	}
+	return m, nil
+}
+
+// ring is an outline as a circular linked list, so corners can be dropped
+// in place. ver counts the changes next to each vertex.
+type ring struct {
+	pts        []model.Point
+	prev, next []int
+	alive      []bool
+	ver        []int
+	n          int
+}
+
+// candidate is one way to drop a corner: the vertex at is removed, or with
+// extend it moves to p and the vertex after it is removed.
+type candidate struct {
+	ring, at, ver int
+	extend        bool
+	p             model.Point
+	cost          float64
+}
+
+// queue is a min-heap of candidates by cost.
+type queue []candidate
+
+func (q queue) Len() int           { return len(q) }
+func (q queue) Less(i, j int) bool { return q[i].cost < q[j].cost }
+func (q queue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *queue) Push(x any)        { *q = append(*q, x.(candidate)) }
+func (q *queue) Pop() any {
+	old := *q
+	x := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return x
+}
+
+type reducer struct {
+	rings []*ring
+	queue queue
+	total int
+	w, h  float64
+}
+
+func newReducer(outlines [][]model.Point, w, h float64) *reducer {
+	r := &reducer{w: w, h: h}
+	for ri, poly := range outlines {
+		n := len(poly)
+		rg := &ring{pts: append([]model.Point(nil), poly...), prev: make([]int, n), next: make([]int, n), alive: make([]bool, n), ver: make([]int, n), n: n}
+		for i := range poly {
+			rg.prev[i], rg.next[i], rg.alive[i] = (i+n-1)%n, (i+1)%n, true
+		}
+		r.rings = append(r.rings, rg)
+		r.total += n
+		for i := range poly {
+			r.push(ri, i)
+		}
+	}
+	return r
+}
+
+// reduce drops the cheapest valid corners until at most max vertices are
+// left. Candidates that are not valid yet are retried once the queue runs
+// dry; it reports false when a retry round changes nothing.
+func (r *reducer) reduce(max int) bool {
+	var deferred []candidate
+	progress := false
+	for r.total > max {
+		if r.queue.Len() == 0 {
+			if !progress {
+				return false
+			}
+			progress = false
+			for _, c := range deferred {
+				heap.Push(&r.queue, c)
+			}
+			deferred = deferred[:0]
+			continue
+		}
+		c := heap.Pop(&r.queue).(candidate)
+		rg := r.rings[c.ring]
+		if !rg.alive[c.at] || rg.ver[c.at] != c.ver || rg.n <= 3 {
+			continue
+		}
+		if !r.valid(c) {
+			deferred = append(deferred, c)
+			continue
+		}
+		at := rg.prev[c.at]
+		if c.extend {
+			at = c.at
+			rg.pts[at] = c.p
+			r.remove(c.ring, rg.next[at])
+		} else {
+			r.remove(c.ring, c.at)
+		}
+		r.tidy(c.ring, at)
+		progress = true
+	}
+	return true
+}
+
+// push queues the candidates anchored at vertex i of ring ri.
+func (r *reducer) push(ri, i int) {
+	rg := r.rings[ri]
+	if !rg.alive[i] || rg.n <= 3 {
+		return
+	}
+	a, b, c, d := rg.pts[rg.prev[i]], rg.pts[i], rg.pts[rg.next[i]], rg.pts[rg.next[rg.next[i]]]
+	if t := cross(a, b, c); t <= 0 {
+		heap.Push(&r.queue, candidate{ring: ri, at: i, ver: rg.ver[i], cost: -t / 2})
+	}
+	if p, ok := meet(a, b, c, d); ok && p.X >= 0 && p.Y >= 0 && p.X <= r.w && p.Y <= r.h {
+		heap.Push(&r.queue, candidate{ring: ri, at: i, ver: rg.ver[i], extend: true, p: p, cost: math.Abs(cross(b, p, c)) / 2})
+	}
+}
+
+func (r *reducer) remove(ri, i int) {
+	rg := r.rings[ri]
+	p, n := rg.prev[i], rg.next[i]
+	rg.next[p], rg.prev[n], rg.alive[i] = n, p, false
+	rg.n--
+	r.total--
+}
+
+// tidy removes repeated points and zero-width spikes left around vertex i
+// of ring ri, then requeues the candidates of the vertices around it.
+func (r *reducer) tidy(ri, i int) {
+	rg := r.rings[ri]
+	for rg.n > 4 {
+		j := r.defect(ri, i)
+		if j < 0 {
+			break
+		}
+		i = rg.prev[j]
+		if rg.pts[j] != rg.pts[rg.next[j]] {
+			r.remove(ri, j)
+		}
+		r.remove(ri, rg.next[i])
+	}
+	for k, j := 0, rg.prev[rg.prev[i]]; k < 5; k, j = k+1, rg.next[j] {
+		rg.ver[j]++
+		r.push(ri, j)
+	}
+}
+
+// defect returns a vertex near i that repeats the next one or is the tip of
+// a spike, or -1.
+func (r *reducer) defect(ri, i int) int {
+	rg := r.rings[ri]
+	for k, j := 0, rg.prev[rg.prev[i]]; k < 5; k, j = k+1, rg.next[j] {
+		if rg.pts[j] == rg.pts[rg.next[j]] || rg.pts[rg.prev[j]] == rg.pts[rg.next[j]] {
+			return j
+		}
+	}
+	return -1
+}
+
+// valid reports whether the triangle c adds to its ring holds no other
+// vertex of any outline and, for an extension, whether its new edges touch
+// no other edge, so outlines stay simple and never overlap. When a corner
+// is cut off, a vertex repeating either end of the cut is a point where the
+// outlines touch and does not block it.
+func (r *reducer) valid(c candidate) bool {
+	rg := r.rings[c.ring]
+	ib, ic := c.at, rg.next[c.at]
+	a, b, cc := rg.pts[rg.prev[ib]], rg.pts[ib], rg.pts[ic]
+	tri := [3]model.Point{a, b, cc}
+	if c.extend {
+		tri = [3]model.Point{b, c.p, cc}
+	}
+	blocks := func(q, qn model.Point) bool {
+		if c.extend {
+			return inTriangle(q, tri[0], tri[1], tri[2]) || intersects(b, c.p, q, qn) || intersects(c.p, cc, q, qn)
+		}
+		return q != a && q != cc && inTriangle(q, tri[0], tri[1], tri[2])
+	}
+	// Every vertex of the ring but a, b and c, with the edge leaving it.
+	for j, k := rg.next[ic], 0; k < rg.n-3; j, k = rg.next[j], k+1 {
+		if blocks(rg.pts[j], rg.pts[rg.next[j]]) {
+			return false
+		}
+	}
+	for ri, other := range r.rings {
+		if ri == c.ring {
+			continue
+		}
+		for j, alive := range other.alive {
+			if alive && blocks(other.pts[j], other.pts[other.next[j]]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (r *reducer) outlines() [][]model.Point {
+	out := make([][]model.Point, 0, len(r.rings))
+	for _, rg := range r.rings {
+		start := 0
+		for !rg.alive[start] {
+			start++
+		}
+		poly := make([]model.Point, 0, rg.n)
+		for i, k := start, 0; k < rg.n; i, k = rg.next[i], k+1 {
+			poly = append(poly, rg.pts[i])
+		}
+		out = append(out, poly)
+	}
+	return out
+}
+
+// meet returns the point beyond the convex corners b and c where the lines
+// through a,b and d,c cross, on the outer side of the edge b,c.
+func meet(a, b, c, d model.Point) (model.Point, bool) {
+	if cross(a, b, c) <= 0 || cross(b, c, d) <= 0 {
+		return model.Point{}, false
+	}
+	u := model.Point{X: b.X - a.X, Y: b.Y - a.Y}
+	v := model.Point{X: c.X - d.X, Y: c.Y - d.Y}
+	den := u.X*v.Y - u.Y*v.X
+	if den == 0 {
+		return model.Point{}, false
+	}
+	bc := model.Point{X: c.X - b.X, Y: c.Y - b.Y}
+	t := (bc.X*v.Y - bc.Y*v.X) / den
+	s := (bc.X*u.Y - bc.Y*u.X) / den
+	if t <= 0 || s <= 0 {
+		return model.Point{}, false
+	}
+	p := model.Point{X: b.X + t*u.X, Y: b.Y + t*u.Y}
+	return p, cross(b, c, p) < 0
+}
+
+func vertexCount(polys [][]model.Point) int {
+	n := 0
+	for _, p := range polys {
+		n += len(p)
+	}
+	return n
+}
+
+// boundingQuad returns the box around polys, wound like the traced outlines.
+func boundingQuad(polys [][]model.Point) []model.Point {
+	x0, y0, x1, y1 := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, poly := range polys {
+		for _, p := range poly {
+			x0, y0 = math.Min(x0, p.X), math.Min(y0, p.Y)
+			x1, y1 = math.Max(x1, p.X), math.Max(y1, p.Y)
+		}
+	}
+	return []model.Point{{X: x0, Y: y0}, {X: x1, Y: y0}, {X: x1, Y: y1}, {X: x0, Y: y1}}
+}
+
+// cross is positive when a, b, c turn the way traced outlines wind.
+func cross(a, b, c model.Point) float64 {
+	return (b.X-a.X)*(c.Y-b.Y) - (b.Y-a.Y)*(c.X-b.X)
+}
+
+// inTriangle reports whether p lies inside or on the triangle a, b, c of
+// either winding.
+func inTriangle(p, a, b, c model.Point) bool {
+	d1, d2, d3 := cross(a, b, p), cross(b, c, p), cross(c, a, p)
+	return (d1 >= 0 && d2 >= 0 && d3 >= 0) || (d1 <= 0 && d2 <= 0 && d3 <= 0)
+}
+
+// intersects reports whether the closed segments p1,p2 and q1,q2 touch.
+func intersects(p1, p2, q1, q2 model.Point) bool {
+	d1, d2 := cross(q1, q2, p1), cross(q1, q2, p2)
+	d3, d4 := cross(p1, p2, q1), cross(p1, p2, q2)
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+	on := func(p, a, b model.Point) bool {
+		return p.X >= math.Min(a.X, b.X) && p.X <= math.Max(a.X, b.X) && p.Y >= math.Min(a.Y, b.Y) && p.Y <= math.Max(a.Y, b.Y)
+	}
+	return (d1 == 0 && on(p1, q1, q2)) || (d2 == 0 && on(p2, q1, q2)) || (d3 == 0 && on(q1, p1, p2)) || (d4 == 0 && on(q2, p1, p2))
+}
@@ -0,0 +1,196 @@
+package mesh
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"pixelc/pkg/model"
+)
+
+func diamond() *image.RGBA {
+	// widths 2,4,6,4,2 centred in an 8x5 frame
+	img := image.NewRGBA(image.Rect(0, 0, 8, 5))
+	for y, half := range []int{1, 2, 3, 2, 1} {
+		for x := 4 - half; x < 4+half; x++ {
+			img.SetRGBA(x, y, color.RGBA{A: 255})
+		}
+	}
+	return img
+}
+
+func TestBuildTriangulatesOutline(t *testing.T) {
+	img := diamond()
+	full, err := Build(img, 64)
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	assertTopology(t, full)
+	if len(full.Vertices) != 20 {
+		t.Fatalf("expected one vertex per outline corner, got %d", len(full.Vertices))
+	}
+	seen := map[model.Point]bool{}
+	for _, v := range full.Vertices {
+		if seen[v] {
+			t.Fatalf("vertex %v duplicated", v)
+		}
+		seen[v] = true
+	}
+	if a := meshArea(full); a != 18 {
+		t.Fatalf("mesh area %v, want the 18 opaque pixels", a)
+	}
+	assertCovers(t, img, full)
+
+	for _, budget := range []int{4, 6, 8, 12, 16} {
+		m, err := Build(img, budget)
+		if err != nil {
+			t.Fatalf("build failed: %v", err)
+		}
+		if len(m.Vertices) > budget || len(m.Vertices) < 3 {
+			t.Fatalf("budget %d: %d vertices", budget, len(m.Vertices))
+		}
+		if budget >= 8 && len(m.Vertices) <= 4 {
+			t.Fatalf("budget %d wasted: %d vertices", budget, len(m.Vertices))
+		}
+		assertTopology(t, m)
+		assertCovers(t, img, m)
+		assertInFrame(t, img, m)
+	}
+}
+
+func TestBuildCoversConcaveSprite(t *testing.T) {
+	// a ring with a notch: concave outline and a hole
+	img := image.NewRGBA(image.Rect(0, 0, 9, 9))
+	for y := 0; y < 9; y++ {
+		for x := 0; x < 9; x++ {
+			dx, dy := x-4, y-4
+			if d := dx*dx + dy*dy; d <= 16 && d >= 4 && !(y == 4 && x > 4) {
+				img.SetRGBA(x, y, color.RGBA{A: 255})
+			}
+		}
+	}
+	for budget := 4; budget <= 40; budget += 4 {
+		m, err := Build(img, budget)
+		if err != nil {
+			t.Fatalf("build failed: %v", err)
+		}
+		if len(m.Vertices) > budget {
+			t.Fatalf("budget %d: %d vertices", budget, len(m.Vertices))
+		}
+		assertTopology(t, m)
+		assertCovers(t, img, m)
+		assertInFrame(t, img, m)
+	}
+}
+
+func TestBuildKeepsIslandsApart(t *testing.T) {
+	// an L shape with a block in its corner
+	img := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	for i := 0; i < 10; i++ {
+		img.SetRGBA(0, i, color.RGBA{A: 255})
+		img.SetRGBA(i, 0, color.RGBA{A: 255})
+	}
+	for y := 3; y < 5; y++ {
+		for x := 3; x < 5; x++ {
+			img.SetRGBA(x, y, color.RGBA{A: 255})
+		}
+	}
+	for budget := 4; budget <= 10; budget++ {
+		m, err := Build(img, budget)
+		if err != nil {
+			t.Fatalf("build failed: %v", err)
+		}
+		assertCovers(t, img, m)
+		for y := 0; y < 10; y++ {
+			for x := 0; x < 10; x++ {
+				px, py := float64(x)+0.37, float64(y)+0.61
+				n := 0
+				for i := 0; i+2 < len(m.Triangles); i += 3 {
+					if covered(model.Mesh{Vertices: m.Vertices, Triangles: m.Triangles[i : i+3]}, px, py) {
+						n++
+					}
+				}
+				if n > 1 {
+					t.Fatalf("budget %d: %v,%v drawn %d times", budget, px, py, n)
+				}
+			}
+		}
+	}
+}
+
+func TestBuildFallsBackToBoundingQuad(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 6, 4))
+	for _, p := range [][2]int{{0, 0}, {3, 1}, {5, 3}} {
+		img.SetRGBA(p[0], p[1], color.RGBA{A: 255})
+	}
+	m, err := Build(img, 8)
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	assertTopology(t, m)
+	if len(m.Vertices) != 4 || meshArea(m) != 24 {
+		t.Fatalf("expected the bounding quad, got %+v", m)
+	}
+}
+
+func TestBuildRejectsTinyBudget(t *testing.T) {
+	if _, err := Build(image.NewRGBA(image.Rect(0, 0, 1, 1)), 3); err == nil {
+		t.Fatal("expected budget error")
+	}
+}
+
+func assertTopology(t *testing.T, m model.Mesh) {
+	t.Helper()
+	if len(m.Triangles) == 0 || len(m.Triangles)%3 != 0 {
+		t.Fatalf("unexpected index count %d", len(m.Triangles))
+	}
+	for _, i := range m.Triangles {
+		if i < 0 || i >= len(m.Vertices) {
+			t.Fatalf("index %d out of range", i)
+		}
+	}
+}
+
+func assertCovers(t *testing.T, img *image.RGBA, m model.Mesh) {
+	t.Helper()
+	b := img.Bounds()
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			if img.RGBAAt(x, y).A > 0 && !covered(m, float64(x)+0.5, float64(y)+0.5) {
+				t.Fatalf("%d vertices leave pixel %d,%d uncovered", len(m.Vertices), x, y)
+			}
+		}
+	}
+}
+
+func assertInFrame(t *testing.T, img *image.RGBA, m model.Mesh) {
+	t.Helper()
+	b := img.Bounds()
+	for _, v := range m.Vertices {
+		if v.X < 0 || v.Y < 0 || v.X > float64(b.Dx()) || v.Y > float64(b.Dy()) {
+			t.Fatalf("vertex %v outside the frame", v)
+		}
+	}
+}
+
+func meshArea(m model.Mesh) float64 {
+	sum := 0.0
+	for i := 0; i+2 < len(m.Triangles); i += 3 {
+		a, b, c := m.Vertices[m.Triangles[i]], m.Vertices[m.Triangles[i+1]], m.Vertices[m.Triangles[i+2]]
+		sum += math.Abs((b.X-a.X)*(c.Y-a.Y)-(b.Y-a.Y)*(c.X-a.X)) / 2
+	}
+	return sum
+}
+
+func covered(m model.Mesh, x, y float64) bool {
+	side := func(a, b model.Point) float64 { return (b.X-a.X)*(y-a.Y) - (b.Y-a.Y)*(x-a.X) }
+	for i := 0; i+2 < len(m.Triangles); i += 3 {
+		a, b, c := m.Vertices[m.Triangles[i]], m.Vertices[m.Triangles[i+1]], m.Vertices[m.Triangles[i+2]]
+		d1, d2, d3 := side(a, b), side(b, c), side(c, a)
+		if (d1 >= 0 && d2 >= 0 && d3 >= 0) || (d1 <= 0 && d2 <= 0 && d3 <= 0) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package nineslice
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"pixelc/pkg/model"
+)
+
+type asepriteData struct {
+	Meta struct {
+		Slices []asepriteSlice `json:"slices"`
+	} `json:"meta"`
+}
+
+type asepriteSlice struct {
+	Name string        `json:"name"`
+	Keys []asepriteKey `json:"keys"`
+}
+
+type asepriteKey struct {
+	Frame  int           `json:"frame"`
+	Bounds asepriteRect  `json:"bounds"`
+	Center *asepriteRect `json:"center"`
+}
+
+type asepriteRect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// AsepriteBorder reads the nine-slice center of an Aseprite JSON export
+// (--data with --list-slices) and returns the border it describes for a
+// w x h frame. It uses the slice named name, else the first slice with a
+// center, taking the key for frame 0. ok is false when no slice has a
+// center.
+func AsepriteBorder(data []byte, name string, w, h int) (border model.Border, ok bool, err error) {
+	var doc asepriteData
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return model.Border{}, false, fmt.Errorf("aseprite data: %w", err)
+	}
+	var key *asepriteKey
+	for _, s := range doc.Meta.Slices {
+		k := centerKey(s)
+		if k == nil {
+			continue
+		}
+		if s.Name == name {
+			key = k
+			break
+		}
+		if key == nil {
+			key = k
+		}
+	}
+	if key == nil {
+		return model.Border{}, false, nil
+	}
+	b, c := key.Bounds, *key.Center
+	border = model.Border{Left: b.X + c.X, Top: b.Y + c.Y, Right: w - (b.X + c.X + c.W), Bottom: h - (b.Y + c.Y + c.H)}
+	if c.W <= 0 || c.H <= 0 || border.Left < 0 || border.Top < 0 || border.Right < 0 || border.Bottom < 0 {
+		return model.Border{}, false, fmt.Errorf("aseprite slice center lies outside the %dx%d frame", w, h)
+	}
+	return border, true, nil
+}
+
+// centerKey returns the key of s for frame 0, or its first key, if it has
+// a nine-slice center.
+func centerKey(s asepriteSlice) *asepriteKey {
+	var key *asepriteKey
+	for i := range s.Keys {
+		if key == nil || s.Keys[i].Frame == 0 {
+			key = &s.Keys[i]
+		}
+		if s.Keys[i].Frame == 0 {
+			break
+		}
+	}
+	if key == nil || key.Center == nil {
+		return nil
+	}
+	return key
+}
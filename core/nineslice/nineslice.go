@@ -0,0 +1,73 @@
+package nineslice
+
+import (
+	"fmt"
+	"image"
+	"path/filepath"
+	"strings"
+
+	"pixelc/pkg/model"
+)
+
+// Suffix marks Android-style nine-patch frames, e.g. panel.9.png.
+const Suffix = ".9"
+
+// IsNinePatch reports whether a file name uses the nine-patch suffix.
+func IsNinePatch(filename string) bool {
+	base := strings.TrimSuffix(filename, filepath.Ext(filename))
+	return strings.HasSuffix(strings.ToLower(base), Suffix)
+}
+
+// SpriteName strips the extension and nine-patch suffix from a file name.
+func SpriteName(filename string) string {
+	base := strings.TrimSuffix(filename, filepath.Ext(filename))
+	if strings.HasSuffix(strings.ToLower(base), Suffix) {
+		base = base[:len(base)-len(Suffix)]
+	}
+	return base
+}
+
+// StripGuides removes the 1px guide frame of a nine-patch image and returns
+// the content image and the border described by the top and left guides.
+// The right and bottom guides (content padding) are ignored.
+func StripGuides(img *image.RGBA) (*image.RGBA, model.Border, error) {
+	if img == nil {
+		return nil, model.Border{}, fmt.Errorf("image is nil")
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w < 3 || h < 3 {
+		return nil, model.Border{}, fmt.Errorf("nine-patch must be at least 3x3")
+	}
+
+	x0, x1 := guideRange(img, w, func(i int) (int, int) { return b.Min.X + i, b.Min.Y })
+	y0, y1 := guideRange(img, h, func(i int) (int, int) { return b.Min.X, b.Min.Y + i })
+	if x0 < 0 || y0 < 0 {
+		return nil, model.Border{}, fmt.Errorf("nine-patch is missing top or left guide pixels")
+	}
+
+	content := image.NewRGBA(image.Rect(0, 0, w-2, h-2))
+	for y := 0; y < h-2; y++ {
+		for x := 0; x < w-2; x++ {
+			content.SetRGBA(x, y, img.RGBAAt(b.Min.X+x+1, b.Min.Y+y+1))
+		}
+	}
+	border := model.Border{Left: x0 - 1, Top: y0 - 1, Right: w - 2 - x1, Bottom: h - 2 - y1}
+	return content, border, nil
+}
+
+// guideRange returns the first and last guide pixel along one edge,
+// excluding the corners, or -1 when the edge has no guide.
+func guideRange(img *image.RGBA, n int, at func(i int) (int, int)) (int, int) {
+	first, last := -1, -1
+	for i := 1; i < n-1; i++ {
+		c := img.RGBAAt(at(i))
+		if c.A == 255 && c.R == 0 && c.G == 0 && c.B == 0 {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	return first, last
+}
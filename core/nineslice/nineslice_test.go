@@ -0,0 +1,79 @@
+package nineslice
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"pixelc/pkg/model"
+)
+
+func TestStripGuides(t *testing.T) {
+	// 8x7 nine-patch: 6x5 content, stretchable x 2..3 and y 1..2 in content space.
+	img := image.NewRGBA(image.Rect(0, 0, 8, 7))
+	black := color.RGBA{A: 255}
+	for x := 3; x <= 4; x++ {
+		img.SetRGBA(x, 0, black)
+	}
+	for y := 2; y <= 3; y++ {
+		img.SetRGBA(0, y, black)
+	}
+	img.SetRGBA(7, 3, black) // right padding guide is ignored
+	img.SetRGBA(1, 1, color.RGBA{R: 200, A: 255})
+
+	content, border, err := StripGuides(img)
+	if err != nil {
+		t.Fatalf("strip failed: %v", err)
+	}
+	if content.Bounds().Dx() != 6 || content.Bounds().Dy() != 5 {
+		t.Fatalf("unexpected content size: %v", content.Bounds())
+	}
+	if content.RGBAAt(0, 0).R != 200 {
+		t.Fatalf("content not shifted past guides")
+	}
+	if border.Left != 2 || border.Right != 2 || border.Top != 1 || border.Bottom != 2 {
+		t.Fatalf("unexpected border: %+v", border)
+	}
+}
+
+func TestStripGuidesRequiresGuides(t *testing.T) {
+	if _, _, err := StripGuides(image.NewRGBA(image.Rect(0, 0, 5, 5))); err == nil {
+		t.Fatal("expected missing guide error")
+	}
+	if _, _, err := StripGuides(image.NewRGBA(image.Rect(0, 0, 2, 2))); err == nil {
+		t.Fatal("expected size error")
+	}
+}
+
+func TestNinePatchNames(t *testing.T) {
+	if !IsNinePatch("panel.9.png") || IsNinePatch("panel_9.png") || IsNinePatch("panel.png") {
+		t.Fatal("unexpected nine-patch detection")
+	}
+	if SpriteName("panel.9.png") != "panel" || SpriteName("hero.png") != "hero" {
+		t.Fatal("unexpected sprite names")
+	}
+}
+
+func TestAsepriteBorder(t *testing.T) {
+	data := []byte(`{"frames":[],"meta":{"slices":[
+		{"name":"hitbox","keys":[{"frame":0,"bounds":{"x":0,"y":0,"w":4,"h":4}}]},
+		{"name":"other","keys":[{"frame":0,"bounds":{"x":0,"y":0,"w":16,"h":12},"center":{"x":1,"y":1,"w":14,"h":10}}]},
+		{"name":"panel","keys":[{"frame":2,"bounds":{"x":0,"y":0,"w":1,"h":1},"center":{"x":0,"y":0,"w":1,"h":1}},{"frame":0,"bounds":{"x":1,"y":1,"w":14,"h":10},"center":{"x":3,"y":2,"w":6,"h":5}}]}
+	]}}`)
+	border, ok, err := AsepriteBorder(data, "panel", 16, 12)
+	if err != nil || !ok {
+		t.Fatalf("expected border, got ok=%v err=%v", ok, err)
+	}
+	if border != (model.Border{Left: 4, Top: 3, Right: 6, Bottom: 4}) {
+		t.Fatalf("unexpected border %+v", border)
+	}
+	if border, ok, _ := AsepriteBorder(data, "button", 16, 12); !ok || border.Left != 1 {
+		t.Fatalf("expected fallback to the first centered slice, got %+v", border)
+	}
+	if _, ok, err := AsepriteBorder([]byte(`{"meta":{}}`), "panel", 16, 12); ok || err != nil {
+		t.Fatalf("expected no border without slices")
+	}
+	if _, _, err := AsepriteBorder(data, "panel", 8, 8); err == nil {
+		t.Fatal("expected center outside the frame to be rejected")
+	}
+}
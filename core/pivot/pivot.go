@@ -14,18 +14,99 @@ func ApplyPivot(s model.Sprite, cfg model.Config) (model.Sprite, error) {
 		return model.Sprite{}, fmt.Errorf("sprite dimensions must be positive")
 	}
 
-	switch cfg.PivotMode {
+	spec, err := model.ParsePivot(cfg.PivotMode)
+	if err != nil {
+		return model.Sprite{}, err
+	}
+	switch spec.Mode {
 	case "center":
 		s.PivotX = float64(s.Width/2) / float64(s.Width)
 		s.PivotY = float64(s.Height/2) / float64(s.Height)
 		return s, nil
 	case "bottom-center":
 		return applyBottomCenter(s)
+	case "top-left":
+		s.PivotX, s.PivotY = 0, 0
+		return s, nil
+	case "top-center":
+		s.PivotX = float64(s.Width/2) / float64(s.Width)
+		s.PivotY = 0
+		return s, nil
+	case "bottom-left":
+		s.PivotX, s.PivotY = 0, 1
+		return s, nil
+	case "left-center":
+		s.PivotX = 0
+		s.PivotY = float64(s.Height/2) / float64(s.Height)
+		return s, nil
+	case "custom":
+		w, h := canvasSize(s)
+		return applyCanvasPoint(s, spec.X*float64(w), spec.Y*float64(h)), nil
+	case "pixel":
+		return applyCanvasPoint(s, spec.X, spec.Y), nil
+	case "anim-center", "anim-bottom-center":
+		out, err := ApplyAnimationPivot([]model.Sprite{s}, spec.Mode)
+		if err != nil {
+			return model.Sprite{}, err
+		}
+		return out[0], nil
 	default:
 		return model.Sprite{}, fmt.Errorf("unsupported pivot mode: %s", cfg.PivotMode)
 	}
 }
 
+// ApplyAnimationPivot computes one anchor in untrimmed canvas space from the
+// union of all frames' trimmed bounds and re-expresses it per frame, so the
+// pivot does not drift as individual frames trim differently.
+func ApplyAnimationPivot(frames []model.Sprite, mode string) ([]model.Sprite, error) {
+	if len(frames) == 0 {
+		return nil, nil
+	}
+	minX, minY := frames[0].OffsetX, frames[0].OffsetY
+	maxX, maxY := minX+frames[0].Width, minY+frames[0].Height
+	for _, f := range frames {
+		if f.Width <= 0 || f.Height <= 0 {
+			return nil, fmt.Errorf("sprite dimensions must be positive")
+		}
+		minX = min(minX, f.OffsetX)
+		minY = min(minY, f.OffsetY)
+		maxX = max(maxX, f.OffsetX+f.Width)
+		maxY = max(maxY, f.OffsetY+f.Height)
+	}
+
+	ax := float64(minX+maxX) / 2
+	var ay float64
+	switch mode {
+	case "anim-center":
+		ay = float64(minY+maxY) / 2
+	case "anim-bottom-center":
+		ay = float64(maxY)
+	default:
+		return nil, fmt.Errorf("unsupported animation pivot mode: %s", mode)
+	}
+
+	out := make([]model.Sprite, len(frames))
+	for i, f := range frames {
+		out[i] = applyCanvasPoint(f, ax, ay)
+	}
+	return out, nil
+}
+
+// applyCanvasPoint re-expresses a point in untrimmed canvas pixels relative
+// to the trimmed frame, so the anchor does not move when trimming changes.
+func applyCanvasPoint(s model.Sprite, x, y float64) model.Sprite {
+	s.PivotX = (x - float64(s.OffsetX)) / float64(s.Width)
+	s.PivotY = (y - float64(s.OffsetY)) / float64(s.Height)
+	return s
+}
+
+func canvasSize(s model.Sprite) (int, int) {
+	if s.SourceWidth > 0 && s.SourceHeight > 0 {
+		return s.SourceWidth, s.SourceHeight
+	}
+	return s.Width, s.Height
+}
+
 func applyBottomCenter(s model.Sprite) (model.Sprite, error) {
 	bounds := s.Image.Bounds()
 	bottomY := -1
@@ -4,6 +4,7 @@ import (
 	"image"
 	"image/color"
 	"math"
+	"strings"
 	"testing"
 
 	"pixelc/pkg/model"
@@ -62,6 +63,96 @@ func TestApplyPivot(t *testing.T) {
 		assertFloat(t, out.PivotX, 0.625)
 		assertFloat(t, out.PivotY, 1.0)
 	})
+
+	t.Run("edge anchored modes", func(t *testing.T) {
+		img := image.NewRGBA(image.Rect(0, 0, 4, 6))
+		s := model.Sprite{Image: img, Width: 4, Height: 6}
+		cases := map[string][2]float64{
+			"top-left":    {0, 0},
+			"top-center":  {0.5, 0},
+			"bottom-left": {0, 1},
+			"left-center": {0, 0.5},
+		}
+		for mode, want := range cases {
+			out, err := ApplyPivot(s, model.Config{PivotMode: mode})
+			if err != nil {
+				t.Fatalf("%s pivot failed: %v", mode, err)
+			}
+			assertFloat(t, out.PivotX, want[0])
+			assertFloat(t, out.PivotY, want[1])
+		}
+	})
+
+	t.Run("canvas pivots survive trimming", func(t *testing.T) {
+		img := image.NewRGBA(image.Rect(0, 0, 2, 4))
+		trimmed := model.Sprite{Image: img, Width: 2, Height: 4, SourceWidth: 8, SourceHeight: 8, OffsetX: 3, OffsetY: 4}
+		out, err := ApplyPivot(trimmed, model.Config{PivotMode: "custom:0.5,1"})
+		if err != nil {
+			t.Fatalf("pivot failed: %v", err)
+		}
+		assertFloat(t, out.PivotX, 0.5)
+		assertFloat(t, out.PivotY, 1.0)
+
+		out, err = ApplyPivot(trimmed, model.Config{PivotMode: "pixel:4,6"})
+		if err != nil {
+			t.Fatalf("pivot failed: %v", err)
+		}
+		assertFloat(t, out.PivotX, 0.5)
+		assertFloat(t, out.PivotY, 0.5)
+	})
+
+	t.Run("untrimmed sprite uses frame as canvas", func(t *testing.T) {
+		img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+		out, err := ApplyPivot(model.Sprite{Image: img, Width: 4, Height: 2}, model.Config{PivotMode: "custom:0.25,0.5"})
+		if err != nil {
+			t.Fatalf("pivot failed: %v", err)
+		}
+		assertFloat(t, out.PivotX, 0.25)
+		assertFloat(t, out.PivotY, 0.5)
+	})
+
+	t.Run("unsupported mode", func(t *testing.T) {
+		img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+		for mode, want := range map[string]string{
+			"custom:2,0": "pivot custom coordinates must be within 0..1",
+			"pixel:4":    "pivot pixel requires x,y coordinates",
+			"pixel:a,b":  `pivot pixel has invalid coordinates: "a,b"`,
+			"middle":     "pivot must be one of",
+		} {
+			_, err := ApplyPivot(model.Sprite{Image: img, Width: 2, Height: 2}, model.Config{PivotMode: mode})
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Fatalf("%s: expected %q, got %v", mode, want, err)
+			}
+		}
+	})
+}
+
+func TestApplyAnimationPivot(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	frames := []model.Sprite{
+		{Name: "walk_1", Image: img, Width: 4, Height: 4, OffsetX: 2, OffsetY: 4},
+		{Name: "walk_2", Image: img, Width: 4, Height: 4, OffsetX: 4, OffsetY: 2},
+	}
+	out, err := ApplyAnimationPivot(frames, "anim-bottom-center")
+	if err != nil {
+		t.Fatalf("pivot failed: %v", err)
+	}
+	// union is x 2..8, y 2..8 so the shared anchor is canvas (5, 8).
+	assertFloat(t, out[0].PivotX, 0.75)
+	assertFloat(t, out[0].PivotY, 1.0)
+	assertFloat(t, out[1].PivotX, 0.25)
+	assertFloat(t, out[1].PivotY, 1.5)
+
+	out, err = ApplyAnimationPivot(frames, "anim-center")
+	if err != nil {
+		t.Fatalf("pivot failed: %v", err)
+	}
+	assertFloat(t, out[0].PivotY, 0.25)
+	assertFloat(t, out[1].PivotY, 0.75)
+
+	if _, err := ApplyAnimationPivot(frames, "center"); err == nil {
+		t.Fatal("expected error for non-animation mode")
+	}
 }
 
 func assertFloat(t *testing.T, got, want float64) {
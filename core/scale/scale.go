@@ -0,0 +1,171 @@
+// Package scale resamples packed atlases into other resolutions.
+package scale
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+
+	"pixelc/internal/imageutil"
+	"pixelc/pkg/model"
+)
+
+// Image resizes src to w x h with filter: "nearest" repeats or skips whole
+// pixels, so pixel art stays sharp at any scale; "area" averages the covered
+// source area, weighted by coverage, for smooth high-resolution art. The
+// default "" enlarges with nearest and shrinks with area.
+func Image(src *image.RGBA, w, h int, filter string) *image.RGBA {
+	b := src.Bounds()
+	if filter == "nearest" || (filter == "" && w >= b.Dx() && h >= b.Dy()) {
+		return nearest(src, w, h)
+	}
+	return area(src, w, h)
+}
+
+func nearest(src *image.RGBA, w, h int) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		sy := b.Min.Y + y*b.Dy()/h
+		for x := 0; x < w; x++ {
+			dst.SetRGBA(x, y, src.RGBAAt(b.Min.X+x*b.Dx()/w, sy))
+		}
+	}
+	return dst
+}
+
+// area averages premultiplied channels, so transparent pixels do not darken
+// the edges they are blended into.
+func area(src *image.RGBA, w, h int) *image.RGBA {
+	b := src.Bounds()
+	fx, fy := float64(b.Dx())/float64(w), float64(b.Dy())/float64(h)
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0, y1 := float64(y)*fy, float64(y+1)*fy
+		for x := 0; x < w; x++ {
+			x0, x1 := float64(x)*fx, float64(x+1)*fx
+			var sum [4]float64
+			total := 0.0
+			for sy := int(y0); float64(sy) < y1 && sy < b.Dy(); sy++ {
+				wy := math.Min(y1, float64(sy+1)) - math.Max(y0, float64(sy))
+				for sx := int(x0); float64(sx) < x1 && sx < b.Dx(); sx++ {
+					wt := wy * (math.Min(x1, float64(sx+1)) - math.Max(x0, float64(sx)))
+					c := src.RGBAAt(b.Min.X+sx, b.Min.Y+sy)
+					sum[0] += wt * float64(c.R)
+					sum[1] += wt * float64(c.G)
+					sum[2] += wt * float64(c.B)
+					sum[3] += wt * float64(c.A)
+					total += wt
+				}
+			}
+			if total == 0 {
+				continue
+			}
+			ch := func(v float64) uint8 { return uint8(math.Min(255, math.Round(v/total))) }
+			dst.SetRGBA(x, y, color.RGBA{R: ch(sum[0]), G: ch(sum[1]), B: ch(sum[2]), A: ch(sum[3])})
+		}
+	}
+	return dst
+}
+
+// Atlas resamples a packed atlas by factor with filter, see Image. Each
+// sprite is resized on its own and placed at its scaled position, so padding
+// never blends neighbours. Rects, source sizes, offsets, borders, polygons
+// and meshes are scaled to match; pivots keep the same point of the frame.
+func Atlas(atlas model.Atlas, img *image.RGBA, factor float64, filter string) (model.Atlas, *image.RGBA, error) {
+	if factor <= 0 {
+		return model.Atlas{}, nil, fmt.Errorf("scale factor must be > 0")
+	}
+	if img == nil {
+		return model.Atlas{}, nil, fmt.Errorf("nil atlas image")
+	}
+	px := func(v int) int { return int(math.Round(float64(v) * factor)) }
+	xs := make([][2]int, 0, len(atlas.Sprites))
+	ys := make([][2]int, 0, len(atlas.Sprites))
+	for _, ps := range atlas.Sprites {
+		xs = append(xs, [2]int{ps.AtlasX, ps.AtlasX + ps.Sprite.Width})
+		ys = append(ys, [2]int{ps.AtlasY, ps.AtlasY + ps.Sprite.Height})
+	}
+	gx, gy := edges(xs, atlas.Width, factor), edges(ys, atlas.Height, factor)
+	out := model.Atlas{Width: max(1, gx[atlas.Width]), Height: max(1, gy[atlas.Height]), Sprites: make([]model.PlacedSprite, 0, len(atlas.Sprites))}
+	for i, ps := range atlas.Sprites {
+		x, y := gx[xs[i][0]], gy[ys[i][0]]
+		w, h := gx[xs[i][1]]-x, gy[ys[i][1]]-y
+		out.Width, out.Height = max(out.Width, x+w), max(out.Height, y+h)
+		out.Sprites = append(out.Sprites, model.PlacedSprite{Sprite: scaleSprite(ps.Sprite, w, h, factor, px), AtlasX: x, AtlasY: y})
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, out.Width, out.Height))
+	for i, ps := range atlas.Sprites {
+		r := image.Rect(ps.AtlasX, ps.AtlasY, ps.AtlasX+ps.Sprite.Width, ps.AtlasY+ps.Sprite.Height).Add(img.Bounds().Min)
+		p := &out.Sprites[i]
+		p.Sprite.Image = Image(img.SubImage(r).(*image.RGBA), p.Sprite.Width, p.Sprite.Height, filter)
+		if err := imageutil.Blit(dst, p.Sprite.Image, p.AtlasX, p.AtlasY); err != nil {
+			return model.Atlas{}, nil, fmt.Errorf("sprite %s: %w", ps.Sprite.Name, err)
+		}
+	}
+	return out, dst, nil
+}
+
+// edges maps the packed coordinates along one axis, the sprite spans and
+// the atlas size, to scaled ones. Each is rounded but kept in order and at
+// least a pixel past the start of every span ending there, so no sprite
+// vanishes and sprites apart before scaling stay apart after it.
+func edges(spans [][2]int, size int, factor float64) map[int]int {
+	starts := map[int][]int{}
+	coords := []int{0, size}
+	for _, s := range spans {
+		starts[s[1]] = append(starts[s[1]], s[0])
+		coords = append(coords, s[0], s[1])
+	}
+	sort.Ints(coords)
+	out := map[int]int{}
+	prev := 0
+	for _, c := range coords {
+		v := max(prev, int(math.Round(float64(c)*factor)))
+		for _, a := range starts[c] {
+			v = max(v, out[a]+1)
+		}
+		out[c], prev = v, v
+	}
+	return out
+}
+
+func scaleSprite(s model.Sprite, w, h int, factor float64, px func(int) int) model.Sprite {
+	out := s
+	out.Width, out.Height = w, h
+	out.X, out.Y = px(s.X), px(s.Y)
+	out.OffsetX, out.OffsetY = px(s.OffsetX), px(s.OffsetY)
+	if s.SourceWidth > 0 && s.SourceHeight > 0 {
+		out.SourceWidth, out.SourceHeight = max(px(s.SourceWidth), out.OffsetX+w), max(px(s.SourceHeight), out.OffsetY+h)
+	}
+	if float64(w) != float64(s.Width)*factor {
+		out.PivotX = s.PivotX * float64(s.Width) * factor / float64(w)
+	}
+	if float64(h) != float64(s.Height)*factor {
+		out.PivotY = s.PivotY * float64(s.Height) * factor / float64(h)
+	}
+	if !s.Border.IsZero() {
+		out.Border = model.Border{Left: min(px(s.Border.Left), w), Top: min(px(s.Border.Top), h)}
+		out.Border.Right = min(px(s.Border.Right), w-out.Border.Left)
+		out.Border.Bottom = min(px(s.Border.Bottom), h-out.Border.Top)
+	}
+	out.Polygons = nil
+	for _, poly := range s.Polygons {
+		out.Polygons = append(out.Polygons, scalePoints(poly, factor))
+	}
+	if len(s.Mesh.Vertices) > 0 {
+		out.Mesh = model.Mesh{Vertices: scalePoints(s.Mesh.Vertices, factor), Triangles: append([]int(nil), s.Mesh.Triangles...)}
+	}
+	return out
+}
+
+func scalePoints(pts []model.Point, factor float64) []model.Point {
+	out := make([]model.Point, len(pts))
+	for i, p := range pts {
+		out[i] = model.Point{X: p.X * factor, Y: p.Y * factor}
+	}
+	return out
+}
@@ -0,0 +1,128 @@
+package scale
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"pixelc/pkg/model"
+)
+
+func TestImageNearestUpscale(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	src.SetRGBA(0, 0, color.RGBA{R: 255, A: 255})
+	src.SetRGBA(1, 0, color.RGBA{B: 255, A: 255})
+	dst := Image(src, 4, 2, "")
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			want := src.RGBAAt(x/2, 0)
+			if got := dst.RGBAAt(x, y); got != want {
+				t.Fatalf("pixel %d,%d = %v, want %v", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestImageAreaDownscale(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	src.SetRGBA(0, 0, color.RGBA{R: 255, A: 255})
+	src.SetRGBA(1, 1, color.RGBA{R: 255, A: 255})
+	dst := Image(src, 1, 1, "")
+	if got := dst.RGBAAt(0, 0); got != (color.RGBA{R: 128, A: 128}) {
+		t.Fatalf("unexpected average %v", got)
+	}
+}
+
+func TestImageFilter(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	src.SetRGBA(0, 0, color.RGBA{R: 255, A: 255})
+	src.SetRGBA(1, 1, color.RGBA{R: 255, A: 255})
+	if got := Image(src, 1, 1, "nearest").RGBAAt(0, 0); got != (color.RGBA{R: 255, A: 255}) {
+		t.Fatalf("nearest shrink blended pixels: %v", got)
+	}
+	if got := Image(src, 4, 4, "area").RGBAAt(1, 1); got != (color.RGBA{R: 255, A: 255}) {
+		t.Fatalf("area enlarge changed a whole pixel: %v", got)
+	}
+	if got := Image(src, 3, 3, "area").RGBAAt(1, 1); got.A == 0 || got.A == 255 {
+		t.Fatalf("area enlarge did not average a split pixel: %v", got)
+	}
+}
+
+func TestAtlasScalesRectsAndPivots(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 4))
+	for x := 1; x < 4; x++ {
+		img.SetRGBA(x, 1, color.RGBA{G: 255, A: 255})
+	}
+	atlas := model.Atlas{Width: 8, Height: 4, Sprites: []model.PlacedSprite{
+		{AtlasX: 1, AtlasY: 1, Sprite: model.Sprite{Name: "a", Width: 3, Height: 2, SourceWidth: 5, SourceHeight: 4, OffsetX: 1, OffsetY: 1, PivotX: 1.0 / 3, PivotY: 0.5,
+			Border: model.Border{Left: 1, Right: 1}, Polygons: [][]model.Point{{{X: 1, Y: 2}}}}},
+		{AtlasX: 5, AtlasY: 1, Sprite: model.Sprite{Name: "b", Width: 2, Height: 2, PivotX: 0.5, PivotY: 1}},
+	}}
+
+	up, upImg, err := Atlas(atlas, img, 2, "")
+	if err != nil {
+		t.Fatalf("scale failed: %v", err)
+	}
+	a := up.Sprites[0]
+	if up.Width != 16 || up.Height != 8 || a.AtlasX != 2 || a.AtlasY != 2 || a.Sprite.Width != 6 || a.Sprite.Height != 4 {
+		t.Fatalf("unexpected 2x layout %+v", up)
+	}
+	if a.Sprite.PivotX != 1.0/3 || a.Sprite.SourceWidth != 10 || a.Sprite.OffsetX != 2 || a.Sprite.Border.Left != 2 || a.Sprite.Polygons[0][0] != (model.Point{X: 2, Y: 4}) {
+		t.Fatalf("unexpected 2x sprite %+v", a.Sprite)
+	}
+	if upImg.RGBAAt(2, 2) != (color.RGBA{G: 255, A: 255}) || upImg.RGBAAt(7, 3) != (color.RGBA{G: 255, A: 255}) || upImg.RGBAAt(7, 4).A != 0 {
+		t.Fatalf("2x pixels not duplicated")
+	}
+
+	down, _, err := Atlas(atlas, img, 0.5, "")
+	if err != nil {
+		t.Fatalf("scale failed: %v", err)
+	}
+	for i, ps := range down.Sprites {
+		s, orig := ps.Sprite, atlas.Sprites[i]
+		if ps.AtlasX+s.Width > down.Width || ps.AtlasY+s.Height > down.Height || s.Width < 1 || s.Height < 1 {
+			t.Fatalf("sprite %s outside the 0.5x atlas: %+v", s.Name, ps)
+		}
+		if got, want := s.PivotX*float64(s.Width), orig.Sprite.PivotX*float64(orig.Sprite.Width)*0.5; got-want > 1e-9 || want-got > 1e-9 {
+			t.Fatalf("sprite %s pivot moved: %v vs %v", s.Name, got, want)
+		}
+	}
+	if _, _, err := Atlas(atlas, img, 0, ""); err == nil {
+		t.Fatalf("expected zero factor to be rejected")
+	}
+}
+
+func TestAtlasKeepsTinySpritesApart(t *testing.T) {
+	// 1px sprites packed edge to edge and a padded pair
+	img := image.NewRGBA(image.Rect(0, 0, 9, 3))
+	atlas := model.Atlas{Width: 9, Height: 3}
+	for i, x := range []int{0, 1, 2, 3, 5, 7} {
+		w := 1
+		if x >= 5 {
+			w = 2
+		}
+		img.SetRGBA(x, 1, color.RGBA{R: uint8(40 * (i + 1)), A: 255})
+		atlas.Sprites = append(atlas.Sprites, model.PlacedSprite{AtlasX: x, AtlasY: 1, Sprite: model.Sprite{Name: string(rune('a' + i)), Width: w, Height: 1}})
+	}
+	for _, factor := range []float64{0.5, 0.25, 0.75, 1.5} {
+		for _, filter := range []string{"", "nearest", "area"} {
+			out, outImg, err := Atlas(atlas, img, factor, filter)
+			if err != nil {
+				t.Fatalf("scale failed: %v", err)
+			}
+			var rects []image.Rectangle
+			for _, ps := range out.Sprites {
+				r := image.Rect(ps.AtlasX, ps.AtlasY, ps.AtlasX+ps.Sprite.Width, ps.AtlasY+ps.Sprite.Height)
+				if r.Empty() || !r.In(outImg.Bounds()) {
+					t.Fatalf("%gx %q: sprite %s at %v outside %v", factor, filter, ps.Sprite.Name, r, outImg.Bounds())
+				}
+				for _, o := range rects {
+					if r.Overlaps(o) {
+						t.Fatalf("%gx %q: sprite %s at %v overlaps %v", factor, filter, ps.Sprite.Name, r, o)
+					}
+				}
+				rects = append(rects, r)
+			}
+		}
+	}
+}
@@ -48,6 +48,12 @@ func TrimSprite(s model.Sprite) (model.Sprite, error) {
 		}
 	}
 
+	if s.SourceWidth == 0 && s.SourceHeight == 0 {
+		s.SourceWidth = bounds.Dx()
+		s.SourceHeight = bounds.Dy()
+	}
+	s.OffsetX += minX - bounds.Min.X
+	s.OffsetY += minY - bounds.Min.Y
 	s.Image = trimmed
 	s.X += minX
 	s.Y += minY
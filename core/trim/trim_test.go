@@ -55,6 +55,18 @@ func TestTrimSprite(t *testing.T) {
 			t.Fatalf("unexpected trim result: %+v", out)
 		}
 	})
+
+	t.Run("records untrimmed canvas", func(t *testing.T) {
+		img := image.NewRGBA(image.Rect(0, 0, 6, 5))
+		img.SetRGBA(2, 3, color.RGBA{A: 255})
+		out, err := TrimSprite(model.Sprite{Image: img, Width: 6, Height: 5})
+		if err != nil {
+			t.Fatalf("trim failed: %v", err)
+		}
+		if out.SourceWidth != 6 || out.SourceHeight != 5 || out.OffsetX != 2 || out.OffsetY != 3 {
+			t.Fatalf("unexpected canvas info: %+v", out)
+		}
+	})
 }
 
 func fillOpaque(img *image.RGBA) {
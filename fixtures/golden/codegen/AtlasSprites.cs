@@ -0,0 +1,32 @@
+// Code generated by pixelc. DO NOT EDIT.
+public static class AtlasSprites
+{
+    public readonly struct Rect
+    {
+        public readonly int X, Y, W, H;
+        public Rect(int x, int y, int w, int h) { X = x; Y = y; W = w; H = h; }
+    }
+
+    public static class Names
+    {
+        public const string Coin = "Coin";
+        public const string Coin2 = "coin";
+        public const string HeroWalk001 = "hero_walk_001";
+        public const string HeroWalk002 = "hero_walk_002";
+    }
+
+    public static class Rects
+    {
+        public static readonly Rect Coin = new Rect(13, 1, 2, 2);
+        public static readonly Rect Coin2 = new Rect(10, 1, 2, 2);
+        public static readonly Rect HeroWalk001 = new Rect(1, 1, 4, 6);
+        public static readonly Rect HeroWalk002 = new Rect(5, 1, 3, 4);
+    }
+
+    public static class Animations
+    {
+        public const string Walk = "walk";
+        public const int WalkFps = 10;
+        public static readonly string[] WalkFrames = { Names.HeroWalk001, Names.HeroWalk002 };
+    }
+}
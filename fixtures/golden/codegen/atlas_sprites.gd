@@ -0,0 +1,20 @@
+# Code generated by pixelc. DO NOT EDIT.
+class_name AtlasSprites
+
+const COIN := "Coin"
+const COIN_2 := "coin"
+const HERO_WALK_001 := "hero_walk_001"
+const HERO_WALK_002 := "hero_walk_002"
+
+const RECTS := {
+	COIN: Rect2i(13, 1, 2, 2),
+	COIN_2: Rect2i(10, 1, 2, 2),
+	HERO_WALK_001: Rect2i(1, 1, 4, 6),
+	HERO_WALK_002: Rect2i(5, 1, 3, 4),
+}
+
+const ANIM_WALK := "walk"
+
+const ANIMATIONS := {
+	ANIM_WALK: {"fps": 10, "frames": [HERO_WALK_001, HERO_WALK_002]},
+}
@@ -0,0 +1,34 @@
+// Code generated by pixelc. DO NOT EDIT.
+
+export interface SpriteRect {
+  x: number;
+  y: number;
+  w: number;
+  h: number;
+}
+
+export const Sprites = {
+  Coin: "Coin",
+  Coin2: "coin",
+  HeroWalk001: "hero_walk_001",
+  HeroWalk002: "hero_walk_002",
+} as const;
+
+export type SpriteName = (typeof Sprites)[keyof typeof Sprites];
+
+export const SpriteRects: Record<SpriteName, SpriteRect> = {
+  [Sprites.Coin]: { x: 13, y: 1, w: 2, h: 2 },
+  [Sprites.Coin2]: { x: 10, y: 1, w: 2, h: 2 },
+  [Sprites.HeroWalk001]: { x: 1, y: 1, w: 4, h: 6 },
+  [Sprites.HeroWalk002]: { x: 5, y: 1, w: 3, h: 4 },
+};
+
+export const Animations = {
+  Walk: "walk",
+} as const;
+
+export type AnimationName = (typeof Animations)[keyof typeof Animations];
+
+export const AnimationFrames: Record<AnimationName, { fps: number; frames: readonly SpriteName[] }> = {
+  [Animations.Walk]: { fps: 10, frames: [Sprites.HeroWalk001, Sprites.HeroWalk002] },
+};
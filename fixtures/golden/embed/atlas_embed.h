@@ -0,0 +1,27 @@
+/* Code generated by pixelc. DO NOT EDIT. */
+#ifndef PIXELC_ATLAS_EMBED_H
+#define PIXELC_ATLAS_EMBED_H
+
+#define PIXELC_ATLAS_WIDTH 16
+#define PIXELC_ATLAS_HEIGHT 8
+#define PIXELC_ATLAS_FRAME_COUNT 3
+
+/* Sprite rect in the atlas PNG; pivot is normalized to the rect, y down. */
+typedef struct pixelc_frame {
+    const char *name;
+    int x, y, w, h;
+    float pivot_x, pivot_y;
+} pixelc_frame;
+
+static const unsigned int pixelc_atlas_png_len = 8;
+static const unsigned char pixelc_atlas_png[] = {
+    0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a,
+};
+
+static const pixelc_frame pixelc_atlas_frames[PIXELC_ATLAS_FRAME_COUNT] = {
+    {"coin", 10, 1, 2, 2, 0.5f, 0.5f},
+    {"hero_walk_001", 1, 1, 4, 6, 0.5f, 1.0f},
+    {"hero_walk_002", 5, 1, 3, 4, 0.5f, 1.0f},
+};
+
+#endif
@@ -0,0 +1,30 @@
+<?xml version="1.0" encoding="UTF-8"?>
+<tileset version="1.10" tiledversion="1.10.2" name="atlas" tilewidth="4" tileheight="6" tilecount="3" columns="0">
+ <grid orientation="orthogonal" width="1" height="1"></grid>
+ <tile id="0" x="10" y="1" width="2" height="2">
+  <properties>
+   <property name="name" value="coin"></property>
+  </properties>
+  <image source="atlas.png" width="16" height="8"></image>
+ </tile>
+ <tile id="1" x="1" y="1" width="4" height="6">
+  <properties>
+   <property name="name" value="hero_walk_001"></property>
+   <property name="state" value="walk"></property>
+   <property name="frame" type="int" value="1"></property>
+  </properties>
+  <image source="atlas.png" width="16" height="8"></image>
+  <animation>
+   <frame tileid="1" duration="100"></frame>
+   <frame tileid="2" duration="100"></frame>
+  </animation>
+ </tile>
+ <tile id="2" x="5" y="1" width="3" height="4">
+  <properties>
+   <property name="name" value="hero_walk_002"></property>
+   <property name="state" value="walk"></property>
+   <property name="frame" type="int" value="2"></property>
+  </properties>
+  <image source="atlas.png" width="16" height="8"></image>
+ </tile>
+</tileset>
@@ -1,6 +1,7 @@
 package imageutil
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
@@ -39,6 +40,14 @@ func SavePNG(path string, img *image.RGBA) error {
 	return nil
 }
 
+func EncodePNG(img *image.RGBA) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("encode png: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
 func HashRGBA(img *image.RGBA) string {
 	h := sha256.Sum256(img.Pix)
 	return hex.EncodeToString(h[:])
@@ -0,0 +1,248 @@
+// Package atlasbin reads and writes pixelc's compact binary atlas metadata
+// (atlas.bin). The layout is documented in docs/atlas-bin.md.
+package atlasbin
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+const (
+	Magic   = "PXAB"
+	Version = 1
+
+	headerSize = 32
+	frameSize  = 36
+)
+
+type Atlas struct {
+	Image      string
+	Width      int
+	Height     int
+	Frames     []Frame
+	Animations []Animation
+}
+
+// Frame mirrors one entry of the Unity atlas.json frames map plus the
+// untrimmed source size and offset. Pivots are normalized, y down.
+type Frame struct {
+	Name         string
+	X            int
+	Y            int
+	W            int
+	H            int
+	SourceWidth  int
+	SourceHeight int
+	OffsetX      int
+	OffsetY      int
+	PivotX       float32
+	PivotY       float32
+	Border       Border
+}
+
+type Border struct {
+	Left   int
+	Top    int
+	Right  int
+	Bottom int
+}
+
+// Animation lists its frames as indexes into Atlas.Frames.
+type Animation struct {
+	State  string
+	FPS    int
+	Frames []int
+}
+
+func Encode(a Atlas) ([]byte, error) {
+	strs := make([]string, 0, len(a.Frames)+len(a.Animations)+1)
+	index := map[string]uint32{}
+	intern := func(s string) (uint32, error) {
+		if i, ok := index[s]; ok {
+			return i, nil
+		}
+		if len(s) > math.MaxUint16 {
+			return 0, fmt.Errorf("string too long: %d bytes", len(s))
+		}
+		index[s] = uint32(len(strs))
+		strs = append(strs, s)
+		return index[s], nil
+	}
+
+	imageIdx, err := intern(a.Image)
+	if err != nil {
+		return nil, err
+	}
+	frames := make([]byte, 0, len(a.Frames)*frameSize)
+	for _, f := range a.Frames {
+		nameIdx, err := intern(f.Name)
+		if err != nil {
+			return nil, err
+		}
+		frames = binary.LittleEndian.AppendUint32(frames, nameIdx)
+		for _, v := range []int{f.X, f.Y, f.W, f.H, f.SourceWidth, f.SourceHeight, f.OffsetX, f.OffsetY} {
+			if frames, err = appendU16(frames, v, f.Name); err != nil {
+				return nil, err
+			}
+		}
+		frames = binary.LittleEndian.AppendUint32(frames, math.Float32bits(f.PivotX))
+		frames = binary.LittleEndian.AppendUint32(frames, math.Float32bits(f.PivotY))
+		for _, v := range []int{f.Border.Left, f.Border.Top, f.Border.Right, f.Border.Bottom} {
+			if frames, err = appendU16(frames, v, f.Name); err != nil {
+				return nil, err
+			}
+		}
+	}
+	anims := make([]byte, 0)
+	for _, an := range a.Animations {
+		stateIdx, err := intern(an.State)
+		if err != nil {
+			return nil, err
+		}
+		anims = binary.LittleEndian.AppendUint32(anims, stateIdx)
+		if anims, err = appendU16(anims, an.FPS, an.State); err != nil {
+			return nil, err
+		}
+		if anims, err = appendU16(anims, len(an.Frames), an.State); err != nil {
+			return nil, err
+		}
+		for _, fi := range an.Frames {
+			if fi < 0 || fi >= len(a.Frames) {
+				return nil, fmt.Errorf("animation %s frame index %d out of range", an.State, fi)
+			}
+			anims = binary.LittleEndian.AppendUint32(anims, uint32(fi))
+		}
+	}
+
+	out := make([]byte, 0, headerSize+len(frames)+len(anims))
+	out = append(out, Magic...)
+	out = binary.LittleEndian.AppendUint16(out, Version)
+	out = binary.LittleEndian.AppendUint16(out, 0) // flags, reserved
+	for _, v := range []int{a.Width, a.Height, len(strs), len(a.Frames), len(a.Animations)} {
+		if v < 0 || uint64(v) > math.MaxUint32 {
+			return nil, fmt.Errorf("header value out of range: %d", v)
+		}
+		out = binary.LittleEndian.AppendUint32(out, uint32(v))
+	}
+	out = binary.LittleEndian.AppendUint32(out, imageIdx)
+	for _, s := range strs {
+		out = binary.LittleEndian.AppendUint16(out, uint16(len(s)))
+		out = append(out, s...)
+	}
+	out = append(out, frames...)
+	return append(out, anims...), nil
+}
+
+func Decode(data []byte) (Atlas, error) {
+	r := reader{data: data}
+	if len(data) < headerSize {
+		return Atlas{}, fmt.Errorf("atlasbin: truncated header")
+	}
+	if string(r.bytes(4)) != Magic {
+		return Atlas{}, fmt.Errorf("atlasbin: bad magic")
+	}
+	if v := r.u16(); v != Version {
+		return Atlas{}, fmt.Errorf("atlasbin: unsupported version %d", v)
+	}
+	if flags := r.u16(); flags != 0 {
+		return Atlas{}, fmt.Errorf("atlasbin: unsupported flags %#x", flags)
+	}
+	a := Atlas{Width: int(r.u32()), Height: int(r.u32())}
+	nStrings, nFrames, nAnims, imageIdx := r.u32(), r.u32(), r.u32(), r.u32()
+
+	strs := make([]string, 0, min(uint64(nStrings), uint64(len(data)/2)))
+	for i := uint32(0); i < nStrings && r.err == nil; i++ {
+		strs = append(strs, string(r.bytes(int(r.u16()))))
+	}
+	str := func(i uint32) string {
+		if int(i) >= len(strs) {
+			r.fail("string index %d out of range", i)
+			return ""
+		}
+		return strs[i]
+	}
+	a.Image = str(imageIdx)
+
+	if uint64(nFrames) > uint64(len(data)/frameSize) {
+		return Atlas{}, fmt.Errorf("atlasbin: frame count %d exceeds data", nFrames)
+	}
+	a.Frames = make([]Frame, 0, nFrames)
+	for i := uint32(0); i < nFrames && r.err == nil; i++ {
+		f := Frame{Name: str(r.u32())}
+		f.X, f.Y, f.W, f.H = int(r.u16()), int(r.u16()), int(r.u16()), int(r.u16())
+		f.SourceWidth, f.SourceHeight = int(r.u16()), int(r.u16())
+		f.OffsetX, f.OffsetY = int(r.u16()), int(r.u16())
+		f.PivotX, f.PivotY = math.Float32frombits(r.u32()), math.Float32frombits(r.u32())
+		f.Border = Border{Left: int(r.u16()), Top: int(r.u16()), Right: int(r.u16()), Bottom: int(r.u16())}
+		a.Frames = append(a.Frames, f)
+	}
+	a.Animations = make([]Animation, 0, min(uint64(nAnims), uint64(len(data)/8)))
+	for i := uint32(0); i < nAnims && r.err == nil; i++ {
+		an := Animation{State: str(r.u32()), FPS: int(r.u16())}
+		n := int(r.u16())
+		for j := 0; j < n && r.err == nil; j++ {
+			fi := r.u32()
+			if fi >= nFrames {
+				r.fail("animation %s frame index %d out of range", an.State, fi)
+			}
+			an.Frames = append(an.Frames, int(fi))
+		}
+		a.Animations = append(a.Animations, an)
+	}
+	if r.err != nil {
+		return Atlas{}, r.err
+	}
+	if r.off != len(data) {
+		return Atlas{}, fmt.Errorf("atlasbin: %d trailing bytes", len(data)-r.off)
+	}
+	return a, nil
+}
+
+func appendU16(b []byte, v int, owner string) ([]byte, error) {
+	if v < 0 || v > math.MaxUint16 {
+		return nil, fmt.Errorf("%s: value %d does not fit in 16 bits", owner, v)
+	}
+	return binary.LittleEndian.AppendUint16(b, uint16(v)), nil
+}
+
+type reader struct {
+	data []byte
+	off  int
+	err  error
+}
+
+func (r *reader) fail(format string, args ...any) {
+	if r.err == nil {
+		r.err = fmt.Errorf("atlasbin: "+format, args...)
+	}
+}
+
+func (r *reader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n > len(r.data)-r.off {
+		r.fail("truncated data at offset %d", r.off)
+		return nil
+	}
+	b := r.data[r.off : r.off+n]
+	r.off += n
+	return b
+}
+
+func (r *reader) u16() uint16 {
+	b := r.bytes(2)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint16(b)
+}
+
+func (r *reader) u32() uint32 {
+	b := r.bytes(4)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(b)
+}
@@ -0,0 +1,73 @@
+package atlasbin
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func sampleAtlas() Atlas {
+	return Atlas{Image: "atlas.png", Width: 64, Height: 32,
+		Frames: []Frame{
+			{Name: "coin", X: 1, Y: 1, W: 2, H: 2, SourceWidth: 2, SourceHeight: 2, PivotX: 0.5, PivotY: 0.5},
+			{Name: "hero_walk_001", X: 4, Y: 1, W: 4, H: 6, SourceWidth: 8, SourceHeight: 8, OffsetX: 1, OffsetY: 2, PivotX: 0.5, PivotY: 1},
+			{Name: "panel", X: 9, Y: 1, W: 8, H: 8, SourceWidth: 8, SourceHeight: 8, PivotX: 0.5, PivotY: 0.5, Border: Border{Left: 2, Top: 2, Right: 3, Bottom: 1}},
+		},
+		Animations: []Animation{{State: "walk", FPS: 12, Frames: []int{1}}},
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	in := sampleAtlas()
+	data, err := Encode(in)
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	if string(data[:4]) != Magic || data[4] != Version || data[5] != 0 {
+		t.Fatalf("unexpected header % x", data[:8])
+	}
+	out, err := Decode(data)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("round trip mismatch\nin:  %+v\nout: %+v", in, out)
+	}
+}
+
+func TestDecodeRejectsCorruptData(t *testing.T) {
+	data, err := Encode(sampleAtlas())
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	badVersion := append([]byte(nil), data...)
+	badVersion[4] = 9
+	badFlags := append([]byte(nil), data...)
+	badFlags[6] = 1
+	cases := map[string][]byte{
+		"truncated header": data[:10],
+		"bad magic":        append([]byte("NOPE"), data[4:]...),
+		"version":          badVersion,
+		"flags":            badFlags,
+		"truncated data":   data[:len(data)-3],
+		"trailing bytes":   append(append([]byte(nil), data...), 0),
+	}
+	for want, in := range cases {
+		if _, err := Decode(in); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%s: got %v", want, err)
+		}
+	}
+}
+
+func TestEncodeRejectsOutOfRange(t *testing.T) {
+	a := sampleAtlas()
+	a.Frames[0].X = 70000
+	if _, err := Encode(a); err == nil {
+		t.Fatal("expected 16-bit overflow error")
+	}
+	a = sampleAtlas()
+	a.Animations[0].Frames = []int{3}
+	if _, err := Encode(a); err == nil {
+		t.Fatal("expected frame index error")
+	}
+}
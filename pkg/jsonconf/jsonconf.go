@@ -0,0 +1,350 @@
+// Package jsonconf decodes pixelc's JSON config files strictly and
+// generates JSON Schemas for them from the same Go types.
+//
+// Decode rejects keys that no field declares, matching json tags exactly,
+// and reports every error with the line and column it was found at. An
+// object member set to null is dropped before decoding, so it leaves the
+// destination field untouched whatever its type. Decoding into a value that
+// holds the defaults makes null mean "use the default" while an explicit
+// zero is kept as written.
+package jsonconf
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Error is a decode error at a position in the input. Line and Column are
+// 1-based; Column counts bytes.
+type Error struct {
+	Line, Column int
+	Msg          string
+}
+
+func (e *Error) Error() string { return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Msg) }
+
+// Decode unmarshals data into v, which must be a non-nil pointer.
+func Decode(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("jsonconf: Decode needs a non-nil pointer, got %T", v)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	// clean is data with null members blanked out; every other byte keeps
+	// its offset, so decode errors still point into data.
+	clean := append([]byte(nil), data...)
+	if err := checkValue(dec, data, clean, rv.Type().Elem(), ""); err != nil {
+		return err
+	}
+	end := dec.InputOffset()
+	if _, err := dec.Token(); err != io.EOF {
+		return errorAt(data, skipSpace(data, end), "unexpected data after the top-level value")
+	}
+	if err := json.Unmarshal(clean, v); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			field := typeErr.Field
+			if field == "" {
+				field = "value"
+			}
+			return errorAt(data, typeErr.Offset, fmt.Sprintf("%s: cannot use %s as %s", field, typeErr.Value, typeName(typeErr.Type)))
+		}
+		return err
+	}
+	return nil
+}
+
+// checkValue reads the next value from dec and reports keys that t has no
+// field for and values of the wrong JSON type. Object members whose value
+// is null are blanked out in clean. path names the value in messages.
+func checkValue(dec *json.Decoder, data, clean []byte, t reflect.Type, path string) error {
+	start := skipSpace(data, dec.InputOffset())
+	tok, err := dec.Token()
+	if err != nil {
+		return syntaxError(data, err)
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if msg := typeMismatch(tok, t); msg != "" {
+		if path == "" {
+			path = "value"
+		}
+		return errorAt(data, start, path+": "+msg)
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return nil
+	}
+	switch delim {
+	case '{':
+		fields := map[string]reflect.Type{}
+		if t.Kind() == reflect.Struct {
+			for name, f := range jsonFields(t) {
+				fields[name] = f.Type
+			}
+		}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return syntaxError(data, err)
+			}
+			key := keyTok.(string)
+			keyEnd := dec.InputOffset()
+			child := joinPath(path, key)
+			var ft reflect.Type
+			switch t.Kind() {
+			case reflect.Struct:
+				if ft, ok = fields[key]; !ok {
+					return errorAt(data, keyStart(data, keyEnd), fmt.Sprintf("unknown key %q", child))
+				}
+			case reflect.Map:
+				ft = t.Elem()
+			default:
+				ft = reflect.TypeOf((*any)(nil)).Elem()
+			}
+			valueStart := skipSpace(data, keyEnd)
+			if err := checkValue(dec, data, clean, ft, child); err != nil {
+				return err
+			}
+			if data[valueStart] == 'n' {
+				dropMember(clean, keyStart(data, keyEnd), dec.InputOffset())
+			}
+		}
+	case '[':
+		elem := reflect.TypeOf((*any)(nil)).Elem()
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			elem = t.Elem()
+		}
+		for i := 0; dec.More(); i++ {
+			child := path + "[" + strconv.Itoa(i) + "]"
+			// A null member keeps its default, but an element has none.
+			if at := skipSpace(data, dec.InputOffset()); data[at] == 'n' && elem.Kind() != reflect.Pointer && elem.Kind() != reflect.Interface {
+				return errorAt(data, at, child+": cannot use null as "+typeName(elem))
+			}
+			if err := checkValue(dec, data, clean, elem, child); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return syntaxError(data, err)
+	}
+	return nil
+}
+
+// typeMismatch describes why tok cannot decode into t, or returns "".
+func typeMismatch(tok json.Token, t reflect.Type) string {
+	if tok == nil || t.Kind() == reflect.Interface {
+		return ""
+	}
+	want := typeName(t)
+	got := ""
+	switch v := tok.(type) {
+	case json.Delim:
+		got = "object"
+		if v == '[' {
+			got = "array"
+		}
+	case string:
+		got = "string"
+	case bool:
+		got = "boolean"
+	case json.Number:
+		got = "number"
+		switch want {
+		case "number":
+			return ""
+		case "integer":
+			var err error
+			if t.Kind() >= reflect.Uint && t.Kind() <= reflect.Uint64 {
+				_, err = strconv.ParseUint(v.String(), 10, t.Bits())
+			} else {
+				_, err = strconv.ParseInt(v.String(), 10, t.Bits())
+			}
+			if err != nil {
+				return fmt.Sprintf("%s is not a valid %s", v, t.Kind())
+			}
+			return ""
+		}
+	}
+	if got == want {
+		return ""
+	}
+	return fmt.Sprintf("cannot use %s as %s", got, want)
+}
+
+// syntaxError positions err at the byte the decoder stopped on.
+func syntaxError(data []byte, err error) error {
+	var syn *json.SyntaxError
+	if errors.As(err, &syn) {
+		offset := syn.Offset - 1
+		if offset < 0 || strings.Contains(syn.Error(), "unexpected end") {
+			offset = int64(len(data))
+		}
+		return errorAt(data, offset, strings.TrimPrefix(err.Error(), "json: "))
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return errorAt(data, int64(len(data)), "unexpected end of input")
+	}
+	return err
+}
+
+// dropMember blanks the object member spanning start to end in data with
+// spaces, together with the comma that separates it from a neighbour.
+func dropMember(data []byte, start, end int64) {
+	next := end
+	for next < int64(len(data)) && strings.IndexByte(" \t\r\n", data[next]) >= 0 {
+		next++
+	}
+	if next < int64(len(data)) && data[next] == ',' {
+		end = next + 1
+	} else {
+		prev := start - 1
+		for prev >= 0 && strings.IndexByte(" \t\r\n", data[prev]) >= 0 {
+			prev--
+		}
+		if prev >= 0 && data[prev] == ',' {
+			start = prev
+		}
+	}
+	for i := start; i < end; i++ {
+		data[i] = ' '
+	}
+}
+
+// skipSpace advances offset past whitespace and the ':' or ',' that
+// separates it from the next value.
+func skipSpace(data []byte, offset int64) int64 {
+	for offset < int64(len(data)) && strings.IndexByte(" \t\r\n:,", data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+// keyStart finds the opening quote of the object key that ends at end.
+func keyStart(data []byte, end int64) int64 {
+	for i := end - 2; i >= 0; i-- {
+		if data[i] == '"' && (i == 0 || data[i-1] != '\\') {
+			return i
+		}
+	}
+	return end
+}
+
+func errorAt(data []byte, offset int64, msg string) error {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	line, col := 1, 1
+	for _, b := range data[:offset] {
+		if b == '\n' {
+			line, col = line+1, 1
+		} else {
+			col++
+		}
+	}
+	return &Error{Line: line, Column: col, Msg: msg}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Bool:
+		return "boolean"
+	}
+	return t.Kind().String()
+}
+
+// jsonFields maps the json names of t's exported fields to the fields.
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f
+	}
+	return fields
+}
+
+// Schema returns a JSON Schema (draft 2020-12) describing the JSON form of
+// v's type. Struct fields may carry a `desc` tag for the description and a
+// `minimum` tag for numbers. Pointer and slice fields also accept null.
+func Schema(v any, title string) ([]byte, error) {
+	s := schemaFor(reflect.TypeOf(v))
+	s["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	s["title"] = title
+	return json.MarshalIndent(s, "", "  ")
+}
+
+func schemaFor(t reflect.Type) map[string]any {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t, nullable = t.Elem(), true
+	}
+	s := map[string]any{}
+	switch t.Kind() {
+	case reflect.Struct:
+		s["type"] = "object"
+		props := map[string]any{}
+		for name, f := range jsonFields(t) {
+			p := schemaFor(f.Type)
+			if f.Type.Kind() == reflect.Slice {
+				p["type"] = []string{"array", "null"}
+			}
+			if d := f.Tag.Get("desc"); d != "" {
+				p["description"] = d
+			}
+			if m := f.Tag.Get("minimum"); m != "" {
+				if n, err := strconv.ParseFloat(m, 64); err == nil {
+					p["minimum"] = n
+				}
+			}
+			props[name] = p
+		}
+		s["properties"] = props
+		s["additionalProperties"] = false
+	case reflect.Map:
+		s["type"] = "object"
+		s["additionalProperties"] = schemaFor(t.Elem())
+	case reflect.Slice, reflect.Array:
+		s["type"] = "array"
+		s["items"] = schemaFor(t.Elem())
+	default:
+		s["type"] = typeName(t)
+	}
+	if nullable {
+		s["type"] = []string{s["type"].(string), "null"}
+	}
+	return s
+}
@@ -0,0 +1,135 @@
+package jsonconf
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testRule struct {
+	Match string `json:"match"`
+	Trim  *bool  `json:"trim"`
+}
+
+type testConfig struct {
+	Padding      int        `json:"padding" desc:"pixels between sprites" minimum:"0"`
+	Connectivity int        `json:"connectivity"`
+	Preset       *string    `json:"preset"`
+	Rules        []testRule `json:"rules"`
+	Tags         map[string]int
+}
+
+func TestDecodeUnknownKeyPosition(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want string
+	}{
+		{"{\n  \"padding\": 1,\n  \"pading\": 4\n}", `3:3: unknown key "pading"`},
+		{`{"rules":[{"match":"a"},{"match":"b","trimm":false}]}`, `1:38: unknown key "rules[1].trimm"`},
+		{`{"Padding": 1}`, `1:2: unknown key "Padding"`},
+		{"{\n\"padding\": \"two\"}", "2:12: padding: cannot use string as integer"},
+		{"{\"padding\": 1,,}", "1:15: invalid character ',' looking for beginning of value"},
+		{`{"padding": 1`, "1:14: unexpected end of JSON input"},
+		{`{"padding": 1.5}`, "1:13: padding: 1.5 is not a valid int"},
+		{`{"rules": {"match": "a"}}`, "1:11: rules: cannot use object as array"},
+		{`{} {}`, "1:4: unexpected data after the top-level value"},
+		{`{"rules": [{"match": "a"}, null]}`, "1:28: rules[1]: cannot use null as object"},
+	} {
+		var cfg testConfig
+		err := Decode([]byte(tc.in), &cfg)
+		var pe *Error
+		if !errors.As(err, &pe) || err.Error() != tc.want {
+			t.Errorf("%s: got %v want %s", tc.in, err, tc.want)
+		}
+	}
+}
+
+func TestDecodeNullKeepsDefaults(t *testing.T) {
+	cfg := testConfig{Padding: 2, Connectivity: 4}
+	in := `{"padding": null, "connectivity": 0, "preset": null, "rules": [{"match": "a", "trim": false}], "Tags": {"x": 1}}`
+	if err := Decode([]byte(in), &cfg); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if cfg.Padding != 2 || cfg.Connectivity != 0 || cfg.Preset != nil {
+		t.Fatalf("null/zero handling wrong: %+v", cfg)
+	}
+	if len(cfg.Rules) != 1 || cfg.Rules[0].Trim == nil || *cfg.Rules[0].Trim || cfg.Tags["x"] != 1 {
+		t.Fatalf("nested values wrong: %+v", cfg)
+	}
+}
+
+func TestDecodeNullKeepsPointerSliceAndMapDefaults(t *testing.T) {
+	preset := "unity"
+	for _, in := range []string{
+		`{"preset": null, "rules": null, "Tags": null}`,
+		"{\n  \"padding\": 3,\n  \"preset\": null\n,\"rules\":null,\"Tags\":null}",
+		`{"rules": null}`,
+	} {
+		cfg := testConfig{Preset: &preset, Rules: []testRule{{Match: "a"}}, Tags: map[string]int{"x": 1}}
+		if err := Decode([]byte(in), &cfg); err != nil {
+			t.Fatalf("%s: decode failed: %v", in, err)
+		}
+		if cfg.Preset != &preset || len(cfg.Rules) != 1 || cfg.Tags["x"] != 1 {
+			t.Fatalf("%s: null replaced a default: %+v", in, cfg)
+		}
+	}
+	var cfg testConfig
+	err := Decode([]byte(`{"preset": null, "padding": "x"}`), &cfg)
+	if err == nil || err.Error() != "1:29: padding: cannot use string as integer" {
+		t.Fatalf("error position moved: %v", err)
+	}
+}
+
+func TestDecodeNullElements(t *testing.T) {
+	var cfg struct {
+		Ignore  []string  `json:"ignore"`
+		Presets []*string `json:"presets"`
+		Any     []any     `json:"any"`
+	}
+	err := Decode([]byte("{\"ignore\": [\"a\",\n  null]}"), &cfg)
+	var pe *Error
+	if !errors.As(err, &pe) || err.Error() != "2:3: ignore[1]: cannot use null as string" {
+		t.Fatalf("null element accepted: %v (%q)", err, cfg.Ignore)
+	}
+	if err := Decode([]byte(`{"presets": [null], "any": [null, 1]}`), &cfg); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if len(cfg.Presets) != 1 || cfg.Presets[0] != nil || len(cfg.Any) != 2 || cfg.Any[0] != nil {
+		t.Fatalf("nullable elements wrong: %+v", cfg)
+	}
+}
+
+func TestSchema(t *testing.T) {
+	data, err := Schema(testConfig{}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s struct {
+		Title                string                     `json:"title"`
+		AdditionalProperties bool                       `json:"additionalProperties"`
+		Properties           map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Title != "test" || s.AdditionalProperties || len(s.Properties) != 5 {
+		t.Fatalf("unexpected schema: %s", data)
+	}
+	for name, want := range map[string]string{
+		"padding": `{"description":"pixels between sprites","minimum":0,"type":"integer"}`,
+		"preset":  `{"type":["string","null"]}`,
+		"rules":   `{"items":{"additionalProperties":false,"properties":{"match":{"type":"string"},"trim":{"type":["boolean","null"]}},"type":"object"},"type":["array","null"]}`,
+		"Tags":    `{"additionalProperties":{"type":"integer"},"type":"object"}`,
+	} {
+		var v any
+		_ = json.Unmarshal(s.Properties[name], &v)
+		got, _ := json.Marshal(v)
+		if string(got) != want {
+			t.Errorf("%s: got %s want %s", name, got, want)
+		}
+	}
+	if !strings.Contains(string(data), `"$schema": "https://json-schema.org/draft/2020-12/schema"`) {
+		t.Fatalf("missing $schema: %s", data)
+	}
+}
@@ -1,6 +1,9 @@
 package model
 
-import "image"
+import (
+	"image"
+	"time"
+)
 
 type Sprite struct {
 	Name   string
@@ -9,8 +12,42 @@ type Sprite struct {
 	Y      int
 	Width  int
 	Height int
-	PivotX float64
-	PivotY float64
+	// SourceWidth/SourceHeight are the untrimmed canvas size and
+	// OffsetX/OffsetY the trimmed frame's position inside that canvas.
+	SourceWidth  int
+	SourceHeight int
+	OffsetX      int
+	OffsetY      int
+	PivotX       float64
+	PivotY       float64
+	Rule         string    // Match of the SpriteRule applied, if any
+	Border       Border    // nine-slice insets; non-zero keeps the sprite untrimmed
+	Polygons     [][]Point // collision outlines in trimmed frame pixels, y down
+	Mesh         Mesh
+}
+
+// Mesh is a triangle mesh covering a sprite's opaque pixels. Vertices are
+// in trimmed frame pixels (y down); Triangles index into Vertices.
+type Mesh struct {
+	Vertices  []Point
+	Triangles []int
+}
+
+type Point struct {
+	X float64
+	Y float64
+}
+
+// Border holds nine-slice insets in pixels from each edge of the frame.
+type Border struct {
+	Left   int
+	Top    int
+	Right  int
+	Bottom int
+}
+
+func (b Border) IsZero() bool {
+	return b == Border{}
 }
 
 type PlacedSprite struct {
@@ -34,8 +71,77 @@ type Animation struct {
 type Config struct {
 	Connectivity int    // 4 or 8
 	Padding      int    // >=0
-	PivotMode    string // "center" | "bottom-center"
+	PivotMode    string // see ParsePivot
 	PowerOfTwo   bool
-	Preset       string // "unity" (v1), others later
+	Preset       string // registered preset name(s), comma-separated
 	FPS          int    // >0 defaults to 12 when zero
+	Rules        []SpriteRule
+
+	Collision          bool    // trace collision polygons from alpha
+	CollisionTolerance float64 // Douglas-Peucker tolerance in pixels, 0 keeps every corner
+	CollisionConvex    bool    // split collision polygons into convex parts
+
+	Mesh            bool // build tight per-sprite triangle meshes
+	MeshMaxVertices int  // vertex budget per sprite mesh, >= 4 when Mesh is set
+
+	Tileset       string   // "" | "collection" | "grid": also write a Tiled .tsx tileset
+	UnityMeta     bool     // also write a Unity atlas.png.meta sprite sheet importer
+	UnityGUIDSeed string   // prefixed to the unit name that seeds the Unity asset guid
+	Codegen       []string // csharp, gdscript, typescript and/or go constant files
+	Embed         string   // "" | "go" | "go-bytes" | "c": also write atlas source for linking
+
+	PluginTimeout time.Duration // limit for exec: preset plugins, 0 uses the default
+
+	Atlases []AtlasGroup // named atlases sprites are routed into; the rest stay in atlas.png
+	Scales  []float64    // extra resolutions written as atlas@<scale>x.png; 1 is always written
+
+	ScaleFilter string // "" | "nearest" | "area": how Scales are resampled; "" enlarges with nearest and shrinks with area
+}
+
+// ExtraScales returns Scales without 1, which is the atlas itself.
+func (c Config) ExtraScales() []float64 {
+	out := make([]float64, 0, len(c.Scales))
+	for _, s := range c.Scales {
+		if s != 1 {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// AtlasGroup routes sprites into a separate atlas written as <Name>.png
+// with the preset files renamed to match. A sprite goes to the group whose
+// Folder (a subfolder of the unit) it was loaded from, else to the first
+// group with a Match glob for its name. Unset fields inherit Config.
+type AtlasGroup struct {
+	Name       string
+	Match      []string
+	Folder     string
+	Padding    *int
+	PivotMode  string
+	PowerOfTwo *bool
+}
+
+// Apply returns cfg with the group's overrides.
+func (g AtlasGroup) Apply(cfg Config) Config {
+	if g.Padding != nil {
+		cfg.Padding = *g.Padding
+	}
+	if g.PivotMode != "" {
+		cfg.PivotMode = g.PivotMode
+	}
+	if g.PowerOfTwo != nil {
+		cfg.PowerOfTwo = *g.PowerOfTwo
+	}
+	cfg.Atlases = nil
+	return cfg
+}
+
+// SpriteRule overrides per-sprite settings for sprites whose name matches
+// the Match glob. The first matching rule wins; empty fields inherit Config.
+type SpriteRule struct {
+	Match     string
+	PivotMode string
+	NoTrim    bool
+	Border    Border
 }
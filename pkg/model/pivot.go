@@ -0,0 +1,50 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// PivotModes lists the named pivot modes accepted in addition to the
+// parameterised custom:x,y and pixel:x,y forms. The anim-* modes share one
+// anchor across all frames of an animation state.
+var PivotModes = []string{"center", "bottom-center", "top-left", "top-center", "bottom-left", "left-center", "anim-center", "anim-bottom-center"}
+
+type PivotSpec struct {
+	Mode string
+	X    float64
+	Y    float64
+}
+
+// ParsePivot parses a pivot mode. custom:x,y is normalized against the
+// untrimmed canvas (0..1, y down); pixel:x,y is in untrimmed canvas pixels.
+func ParsePivot(mode string) (PivotSpec, error) {
+	for _, m := range PivotModes {
+		if mode == m {
+			return PivotSpec{Mode: m}, nil
+		}
+	}
+	kind, args, ok := strings.Cut(mode, ":")
+	if !ok || (kind != "custom" && kind != "pixel") {
+		return PivotSpec{}, fmt.Errorf("pivot must be one of %s, custom:x,y or pixel:x,y", strings.Join(PivotModes, ", "))
+	}
+	xs, ys, ok := strings.Cut(args, ",")
+	if !ok {
+		return PivotSpec{}, fmt.Errorf("pivot %s requires x,y coordinates", kind)
+	}
+	x, errX := strconv.ParseFloat(strings.TrimSpace(xs), 64)
+	y, errY := strconv.ParseFloat(strings.TrimSpace(ys), 64)
+	if errX != nil || errY != nil || math.IsNaN(x) || math.IsNaN(y) || math.IsInf(x, 0) || math.IsInf(y, 0) {
+		return PivotSpec{}, fmt.Errorf("pivot %s has invalid coordinates: %q", kind, args)
+	}
+	if kind == "custom" && (x < 0 || x > 1 || y < 0 || y > 1) {
+		return PivotSpec{}, fmt.Errorf("pivot custom coordinates must be within 0..1")
+	}
+	return PivotSpec{Mode: kind, X: x, Y: y}, nil
+}
+
+func IsAnimationPivot(mode string) bool {
+	return mode == "anim-center" || mode == "anim-bottom-center"
+}
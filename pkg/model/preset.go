@@ -0,0 +1,15 @@
+package model
+
+import "strings"
+
+// Presets splits Preset on commas, so one compile can run several exporters.
+// Names are checked against the exporter registry in core, not here.
+func (c Config) Presets() []string {
+	out := make([]string, 0)
+	for _, p := range strings.Split(c.Preset, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -2,6 +2,9 @@ package model
 
 import (
 	"fmt"
+	"path"
+	"regexp"
+	"strings"
 )
 
 func (c Config) Validate() error {
@@ -11,15 +14,126 @@ func (c Config) Validate() error {
 	if c.Padding < 0 {
 		return fmt.Errorf("padding must be >= 0")
 	}
-	if c.PivotMode != "center" && c.PivotMode != "bottom-center" {
-		return fmt.Errorf("pivot must be center or bottom-center")
+	if _, err := ParsePivot(c.PivotMode); err != nil {
+		return err
 	}
-	if c.Preset != "unity" && c.Preset != "godot" && c.Preset != "custom" {
-		return fmt.Errorf("preset must be unity, godot, or custom")
+	if len(c.Presets()) == 0 {
+		return fmt.Errorf("preset is required")
 	}
 	if c.FPS < 0 {
 		return fmt.Errorf("fps must be >= 0")
 	}
+	if c.PluginTimeout < 0 {
+		return fmt.Errorf("plugin timeout must be >= 0")
+	}
+	if c.CollisionTolerance < 0 {
+		return fmt.Errorf("collision tolerance must be >= 0")
+	}
+	if c.Tileset != "" && c.Tileset != "collection" && c.Tileset != "grid" {
+		return fmt.Errorf("tileset must be collection or grid")
+	}
+	if c.Mesh && c.MeshMaxVertices < 4 {
+		return fmt.Errorf("mesh max vertices must be >= 4")
+	}
+	switch c.Embed {
+	case "", "go", "go-bytes", "c":
+	default:
+		return fmt.Errorf("embed must be go, go-bytes, or c")
+	}
+	for _, lang := range c.Codegen {
+		switch lang {
+		case "csharp", "gdscript", "typescript", "go":
+		default:
+			return fmt.Errorf("codegen must be csharp, gdscript, typescript, or go")
+		}
+	}
+	for i, r := range c.Rules {
+		if err := r.Validate(); err != nil {
+			return fmt.Errorf("rule %d: %w", i, err)
+		}
+	}
+	if len(c.Atlases) > 0 {
+		if c.Embed != "" || len(c.Codegen) > 0 {
+			return fmt.Errorf("atlases cannot be combined with embed or codegen")
+		}
+		if c.Tileset == "grid" {
+			return fmt.Errorf("atlases cannot be combined with a grid tileset")
+		}
+	}
+	if c.ScaleFilter != "" && c.ScaleFilter != "nearest" && c.ScaleFilter != "area" {
+		return fmt.Errorf("scale filter must be nearest or area")
+	}
+	scales := map[float64]bool{}
+	for _, s := range c.Scales {
+		if !(s > 0 && s <= 8) {
+			return fmt.Errorf("scale %g must be > 0 and <= 8", s)
+		}
+		if scales[s] {
+			return fmt.Errorf("scale %g listed twice", s)
+		}
+		scales[s] = true
+	}
+	seen := map[string]bool{}
+	for i, g := range c.Atlases {
+		if err := g.Validate(); err != nil {
+			return fmt.Errorf("atlas %d: %w", i, err)
+		}
+		if seen[g.Name] {
+			return fmt.Errorf("atlas %s listed twice", g.Name)
+		}
+		seen[g.Name] = true
+	}
+	return nil
+}
+
+var atlasNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func (g AtlasGroup) Validate() error {
+	if !atlasNamePattern.MatchString(g.Name) {
+		return fmt.Errorf("name %q must be letters, digits, '-' or '_'", g.Name)
+	}
+	if g.Name == "atlas" || g.Name == "report" {
+		return fmt.Errorf("name %q is reserved", g.Name)
+	}
+	if len(g.Match) == 0 && g.Folder == "" {
+		return fmt.Errorf("match or folder is required")
+	}
+	for _, m := range g.Match {
+		if _, err := path.Match(m, ""); err != nil || m == "" {
+			return fmt.Errorf("invalid match pattern %q", m)
+		}
+	}
+	if g.Folder != "" {
+		if clean := path.Clean(g.Folder); clean != g.Folder || clean == "." || clean == ".." || strings.HasPrefix(clean, "../") || path.IsAbs(clean) {
+			return fmt.Errorf("folder %q must be a relative path inside the unit", g.Folder)
+		}
+	}
+	if g.Padding != nil && *g.Padding < 0 {
+		return fmt.Errorf("padding must be >= 0")
+	}
+	if g.PivotMode != "" {
+		if _, err := ParsePivot(g.PivotMode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r SpriteRule) Validate() error {
+	if r.Match == "" {
+		return fmt.Errorf("match pattern is required")
+	}
+	if _, err := path.Match(r.Match, ""); err != nil {
+		return fmt.Errorf("invalid match pattern %q", r.Match)
+	}
+	if r.PivotMode != "" {
+		if _, err := ParsePivot(r.PivotMode); err != nil {
+			return err
+		}
+	}
+	if r.Border.Left < 0 || r.Border.Top < 0 || r.Border.Right < 0 || r.Border.Bottom < 0 {
+		return fmt.Errorf("border values must be >= 0")
+	}
 	return nil
 }
 
@@ -37,6 +151,9 @@ func (a Atlas) Validate() error {
 		if ps.Sprite.X < 0 || ps.Sprite.Y < 0 {
 			return fmt.Errorf("sprite %d source position must be non-negative", i)
 		}
+		if b := ps.Sprite.Border; b.Left < 0 || b.Top < 0 || b.Right < 0 || b.Bottom < 0 || b.Left+b.Right > ps.Sprite.Width || b.Top+b.Bottom > ps.Sprite.Height {
+			return fmt.Errorf("sprite %d border exceeds its dimensions", i)
+		}
 	}
 	return nil
 }
@@ -7,13 +7,55 @@ func TestConfigValidate(t *testing.T) {
 	if err := valid.Validate(); err != nil {
 		t.Fatalf("expected valid config, got %v", err)
 	}
+	for _, preset := range []string{"texturepacker-hash", "texturepacker-array"} {
+		valid.Preset = preset
+		if err := valid.Validate(); err != nil {
+			t.Fatalf("expected preset %s to be valid, got %v", preset, err)
+		}
+	}
+
+	scaled := valid
+	scaled.Scales = []float64{1, 2, 4, 0.5}
+	if err := scaled.Validate(); err != nil {
+		t.Fatalf("expected scales to be valid, got %v", err)
+	}
+	if got := scaled.ExtraScales(); len(got) != 3 || got[0] != 2 {
+		t.Fatalf("unexpected extra scales %v", got)
+	}
+
+	grouped := valid
+	grouped.Atlases = []AtlasGroup{{Name: "ui", Match: []string{"ui_*"}}, {Name: "fx", Folder: "effects/fx", PivotMode: "bottom-center"}}
+	if err := grouped.Validate(); err != nil {
+		t.Fatalf("expected atlases to be valid, got %v", err)
+	}
 
 	cases := []Config{
 		{Connectivity: 5, Padding: 0, PivotMode: "center", Preset: "unity"},
 		{Connectivity: 4, Padding: -1, PivotMode: "center", Preset: "unity"},
 		{Connectivity: 4, Padding: 0, PivotMode: "top", Preset: "unity"},
-		{Connectivity: 4, Padding: 0, PivotMode: "center", Preset: "invalid"},
+		{Connectivity: 4, Padding: 0, PivotMode: "center", Preset: " , "},
 		{Connectivity: 4, Padding: 0, PivotMode: "center", Preset: "unity", FPS: -1},
+		{Connectivity: 4, Padding: 0, PivotMode: "custom:0.5", Preset: "unity"},
+		{Connectivity: 4, Padding: 0, PivotMode: "custom:1.5,0", Preset: "unity"},
+		{Connectivity: 4, Padding: 0, PivotMode: "pixel:a,b", Preset: "unity"},
+		{Connectivity: 4, Padding: 0, PivotMode: "center", Preset: "unity", Rules: []SpriteRule{{PivotMode: "center"}}},
+		{Connectivity: 4, Padding: 0, PivotMode: "center", Preset: "unity", Rules: []SpriteRule{{Match: "hero_*", PivotMode: "top"}}},
+		{Connectivity: 4, Padding: 0, PivotMode: "center", Preset: "unity", Rules: []SpriteRule{{Match: "[", PivotMode: "center"}}},
+		{Connectivity: 4, Padding: 0, PivotMode: "center", Preset: "unity", Rules: []SpriteRule{{Match: "ui_*", Border: Border{Left: -1}}}},
+		{Connectivity: 4, Padding: 0, PivotMode: "center", Preset: "unity", Codegen: []string{"rust"}},
+		{Connectivity: 4, Padding: 0, PivotMode: "center", Preset: "unity", Embed: "rust"},
+		{Connectivity: 4, Padding: 0, PivotMode: "center", Preset: "unity", PluginTimeout: -1},
+		{Connectivity: 4, Padding: 0, PivotMode: "center", Preset: "unity", Atlases: []AtlasGroup{{Name: "ui"}}},
+		{Connectivity: 4, Padding: 0, PivotMode: "center", Preset: "unity", Atlases: []AtlasGroup{{Name: "atlas", Match: []string{"*"}}}},
+		{Connectivity: 4, Padding: 0, PivotMode: "center", Preset: "unity", Atlases: []AtlasGroup{{Name: "ui/x", Match: []string{"*"}}}},
+		{Connectivity: 4, Padding: 0, PivotMode: "center", Preset: "unity", Atlases: []AtlasGroup{{Name: "ui", Folder: "../ui"}}},
+		{Connectivity: 4, Padding: 0, PivotMode: "center", Preset: "unity", Atlases: []AtlasGroup{{Name: "ui", Match: []string{"ui_*"}}, {Name: "ui", Folder: "ui"}}},
+		{Connectivity: 4, Padding: 0, PivotMode: "center", Preset: "unity", Atlases: []AtlasGroup{{Name: "ui", Match: []string{"ui_*"}, PivotMode: "top"}}},
+		{Connectivity: 4, Padding: 0, PivotMode: "center", Preset: "unity", Scales: []float64{0}},
+		{Connectivity: 4, Padding: 0, PivotMode: "center", Preset: "unity", Scales: []float64{2, 16}},
+		{Connectivity: 4, Padding: 0, PivotMode: "center", Preset: "unity", Scales: []float64{2, 2}},
+		{Connectivity: 4, Padding: 0, PivotMode: "center", Preset: "unity", Scales: []float64{0.5}, ScaleFilter: "bilinear"},
+		{Connectivity: 4, Padding: 0, PivotMode: "center", Preset: "unity", Embed: "go", Atlases: []AtlasGroup{{Name: "ui", Match: []string{"ui_*"}}}},
 	}
 
 	for _, cfg := range cases {
@@ -23,6 +65,21 @@ func TestConfigValidate(t *testing.T) {
 	}
 }
 
+func TestParsePivot(t *testing.T) {
+	for _, mode := range []string{"center", "bottom-center", "top-left", "top-center", "bottom-left", "left-center", "custom:0,1", "pixel:-2,40.5"} {
+		if _, err := ParsePivot(mode); err != nil {
+			t.Fatalf("expected %q to parse, got %v", mode, err)
+		}
+	}
+	spec, err := ParsePivot("pixel: 3, 7.5")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if spec.Mode != "pixel" || spec.X != 3 || spec.Y != 7.5 {
+		t.Fatalf("unexpected spec: %+v", spec)
+	}
+}
+
 func TestAtlasValidate(t *testing.T) {
 	valid := Atlas{Width: 32, Height: 32, Sprites: []PlacedSprite{{Sprite: Sprite{Width: 16, Height: 16}, AtlasX: 0, AtlasY: 0}}}
 	if err := valid.Validate(); err != nil {
@@ -35,6 +92,7 @@ func TestAtlasValidate(t *testing.T) {
 		{Width: 10, Height: 10, Sprites: []PlacedSprite{{Sprite: Sprite{Width: -1, Height: 1}, AtlasX: 0, AtlasY: 0}}},
 		{Width: 10, Height: 10, Sprites: []PlacedSprite{{Sprite: Sprite{Width: 1, Height: 1, X: -1}, AtlasX: 0, AtlasY: 0}}},
 		{Width: 10, Height: 10, Sprites: []PlacedSprite{{Sprite: Sprite{Width: 1, Height: 1}, AtlasX: -1, AtlasY: 0}}},
+		{Width: 10, Height: 10, Sprites: []PlacedSprite{{Sprite: Sprite{Width: 4, Height: 4, Border: Border{Left: 3, Right: 2}}}}},
 	}
 
 	for _, atlas := range cases {
@@ -0,0 +1,52 @@
+package schema
+
+// GodotAtlasJSON is the godot preset's atlas.godot.json. Frame fields are
+// named after the Godot properties they feed.
+type GodotAtlasJSON struct {
+	Image      string                    `json:"image"`
+	Size       GodotSize                 `json:"size"`
+	Frames     map[string]GodotFrame     `json:"frames"`
+	Animations map[string]GodotAnimation `json:"animations,omitempty"`
+}
+
+type GodotSize struct {
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type GodotRect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// GodotFrame positions are in pixels, y down. Region and Margin are the
+// AtlasTexture properties: Margin.X/Y is the trimmed frame's position in its
+// untrimmed canvas and Margin.W/H the size trimmed away. Offset is the
+// Sprite2D offset, with centered off, that puts the pivot on the node
+// origin; Polygons share that origin, so each can be assigned to a
+// CollisionPolygon2D.polygon as is. StyleBox is set for nine-slice frames.
+type GodotFrame struct {
+	Region   GodotRect      `json:"region"`
+	Margin   GodotRect      `json:"margin"`
+	Offset   [2]float64     `json:"offset"`
+	Polygons [][][2]float64 `json:"polygons,omitempty"`
+	StyleBox *GodotStyleBox `json:"style_box,omitempty"`
+}
+
+// GodotStyleBox holds the StyleBoxTexture properties of a nine-slice
+// frame; region_rect is the frame's Region.
+type GodotStyleBox struct {
+	RegionRect          GodotRect `json:"region_rect"`
+	TextureMarginLeft   int       `json:"texture_margin_left"`
+	TextureMarginTop    int       `json:"texture_margin_top"`
+	TextureMarginRight  int       `json:"texture_margin_right"`
+	TextureMarginBottom int       `json:"texture_margin_bottom"`
+}
+
+// GodotAnimation maps onto a SpriteFrames animation.
+type GodotAnimation struct {
+	Speed  int      `json:"speed"`
+	Frames []string `json:"frames"`
+}
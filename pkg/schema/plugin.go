@@ -0,0 +1,60 @@
+package schema
+
+// PluginProtocolVersion is the version of the exec: exporter protocol.
+// pixelc writes a PluginRequest as JSON to the plugin's stdin and reads a
+// PluginResponse from its stdout.
+const PluginProtocolVersion = 1
+
+type PluginRequest struct {
+	Version    int               `json:"version"`
+	App        string            `json:"app"`
+	AppVersion string            `json:"app_version"`
+	Pages      []PluginPage      `json:"pages"`
+	Frames     []PluginFrame     `json:"frames"`
+	Animations []PluginAnimation `json:"animations"`
+}
+
+// PluginPage is one atlas image. Frames refer to pages by index.
+type PluginPage struct {
+	Image  string `json:"image"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// PluginFrame positions are in pixels with the origin top-left. Pivots are
+// normalized to the frame, y down.
+type PluginFrame struct {
+	Name         string         `json:"name"`
+	Page         int            `json:"page"`
+	X            int            `json:"x"`
+	Y            int            `json:"y"`
+	W            int            `json:"w"`
+	H            int            `json:"h"`
+	SourceWidth  int            `json:"source_w"`
+	SourceHeight int            `json:"source_h"`
+	OffsetX      int            `json:"offset_x"`
+	OffsetY      int            `json:"offset_y"`
+	PivotX       float64        `json:"pivot_x"`
+	PivotY       float64        `json:"pivot_y"`
+	Border       *UnityBorder   `json:"border,omitempty"`
+	Polygons     [][]UnityPoint `json:"polygons,omitempty"`
+}
+
+type PluginAnimation struct {
+	State  string   `json:"state"`
+	FPS    int      `json:"fps"`
+	Frames []string `json:"frames"`
+}
+
+type PluginResponse struct {
+	Version int          `json:"version"`
+	Files   []PluginFile `json:"files"`
+	Error   string       `json:"error,omitempty"`
+}
+
+// PluginFile is one output file. Encoding is "utf8" (default) or "base64".
+type PluginFile struct {
+	Name     string `json:"name"`
+	Encoding string `json:"encoding,omitempty"`
+	Data     string `json:"data"`
+}
@@ -0,0 +1,55 @@
+package schema
+
+type TPRect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type TPSize struct {
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type TPPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+type TPFrame struct {
+	Frame            TPRect  `json:"frame"`
+	Rotated          bool    `json:"rotated"`
+	Trimmed          bool    `json:"trimmed"`
+	SpriteSourceSize TPRect  `json:"spriteSourceSize"`
+	SourceSize       TPSize  `json:"sourceSize"`
+	Pivot            TPPoint `json:"pivot"`
+}
+
+type TPArrayFrame struct {
+	Filename string `json:"filename"`
+	TPFrame
+}
+
+type TPMeta struct {
+	App     string `json:"app"`
+	Version string `json:"version"`
+	Image   string `json:"image"`
+	Format  string `json:"format"`
+	Size    TPSize `json:"size"`
+	Scale   string `json:"scale"`
+
+	Variants []Variant `json:"variants,omitempty"`
+}
+
+type TPHashJSON struct {
+	Frames     map[string]TPFrame  `json:"frames"`
+	Animations map[string][]string `json:"animations,omitempty"`
+	Meta       TPMeta              `json:"meta"`
+}
+
+type TPArrayJSON struct {
+	Frames     []TPArrayFrame      `json:"frames"`
+	Animations map[string][]string `json:"animations,omitempty"`
+	Meta       TPMeta              `json:"meta"`
+}
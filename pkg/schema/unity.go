@@ -8,6 +8,17 @@ type UnityMeta struct {
 		W int `json:"w"`
 		H int `json:"h"`
 	} `json:"size"`
+	Scale    float64   `json:"scale,omitempty"`
+	Variants []Variant `json:"variants,omitempty"`
+}
+
+// Variant is one resolution of an atlas written with extra scales. The
+// metadata of every resolution lists all of them, scale 1 included.
+type Variant struct {
+	Scale float64 `json:"scale"`
+	Image string  `json:"image"`
+	W     int     `json:"w"`
+	H     int     `json:"h"`
 }
 
 type UnityFrame struct {
@@ -21,6 +32,32 @@ type UnityFrame struct {
 		X float64 `json:"x"`
 		Y float64 `json:"y"`
 	} `json:"pivot"`
+	Border   *UnityBorder   `json:"border,omitempty"`
+	Polygons [][]UnityPoint `json:"polygons,omitempty"`
+	Mesh     *UnityMesh     `json:"mesh,omitempty"`
+}
+
+// UnityMesh is a sprite's tight triangle mesh. Vertices are in frame pixels
+// and UVs are normalized atlas coordinates, both with y down.
+type UnityMesh struct {
+	Vertices  []UnityPoint `json:"vertices"`
+	UVs       []UnityPoint `json:"uvs"`
+	Triangles []int        `json:"triangles"`
+}
+
+// UnityPoint is a position in frame pixels, origin top-left, y down.
+type UnityPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// UnityBorder holds nine-slice insets in pixels. The edge names match
+// Godot's StyleBoxTexture texture_margin_* properties.
+type UnityBorder struct {
+	Left   int `json:"left"`
+	Top    int `json:"top"`
+	Right  int `json:"right"`
+	Bottom int `json:"bottom"`
 }
 
 type UnityAnimation struct {